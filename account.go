@@ -0,0 +1,85 @@
+package microstellar
+
+// AccountSigner is one entry in an account's signer set: its key type,
+// public key and signing weight.
+type AccountSigner struct {
+	Type      string
+	Weight    uint32
+	PublicKey string
+}
+
+// Account is a Stellar account as loaded from Horizon: its sequence
+// number, thresholds, signers and balances.
+type Account struct {
+	Address       string
+	sequence      uint64
+	masterWeight  uint32
+	lowThreshold  uint32
+	medThreshold  uint32
+	highThreshold uint32
+	balances      map[string]string // asset code (or "native") -> balance
+	Signers       []AccountSigner
+}
+
+// Sequence returns the account's current sequence number, as last seen
+// on Horizon.
+func (a *Account) Sequence() uint64 {
+	return a.sequence
+}
+
+// GetMasterWeight returns the signing weight of the account's master
+// key. It's 0 if the master key has been removed as a signer (e.g. via
+// SetMasterWeight(0)).
+func (a *Account) GetMasterWeight() uint32 {
+	return a.masterWeight
+}
+
+// GetLowThreshold returns the weight required for low-threshold
+// operations, as set by SetThresholds.
+func (a *Account) GetLowThreshold() uint32 {
+	return a.lowThreshold
+}
+
+// GetMedThreshold returns the weight required for medium-threshold
+// operations (e.g. Pay), as set by SetThresholds.
+func (a *Account) GetMedThreshold() uint32 {
+	return a.medThreshold
+}
+
+// GetHighThreshold returns the weight required for high-threshold
+// operations (e.g. SetThresholds itself), as set by SetThresholds.
+func (a *Account) GetHighThreshold() uint32 {
+	return a.highThreshold
+}
+
+// GetNativeBalance returns the account's lumen balance, as a decimal
+// string.
+func (a *Account) GetNativeBalance() string {
+	return a.balances["native"]
+}
+
+// GetBalance returns the account's balance of asset, as a decimal
+// string, or "" if the account has no trustline for it.
+func (a *Account) GetBalance(asset *Asset) string {
+	if asset.IsNative() {
+		return a.GetNativeBalance()
+	}
+
+	return a.balances[asset.Code+":"+asset.Issuer]
+}
+
+func (a *Account) balanceKey(asset *Asset) string {
+	if asset.IsNative() {
+		return "native"
+	}
+
+	return asset.Code + ":" + asset.Issuer
+}
+
+func (a *Account) setBalance(asset *Asset, balance string) {
+	if a.balances == nil {
+		a.balances = map[string]string{}
+	}
+
+	a.balances[a.balanceKey(asset)] = balance
+}
@@ -0,0 +1,45 @@
+package microstellar
+
+import "fmt"
+
+// AssetType is the Stellar asset type: native (lumens) or one of the two
+// credit asset encodings, which only differ in the maximum length of
+// the asset code.
+type AssetType int
+
+// AssetType values.
+const (
+	NativeType AssetType = iota
+	Credit4Type
+	Credit12Type
+)
+
+// Asset identifies a Stellar asset: native lumens, or a code/issuer pair
+// for a credit asset.
+type Asset struct {
+	Code   string
+	Issuer string
+	Type   AssetType
+}
+
+// NewAsset builds a credit asset with the given code, issuer and type
+// (Credit4Type or Credit12Type).
+func NewAsset(code string, issuer string, assetType AssetType) *Asset {
+	return &Asset{Code: code, Issuer: issuer, Type: assetType}
+}
+
+// NativeAsset is the well-known native lumen asset.
+var NativeAsset = &Asset{Type: NativeType}
+
+// IsNative reports whether a is the native lumen asset.
+func (a *Asset) IsNative() bool {
+	return a == nil || a.Type == NativeType
+}
+
+func (a *Asset) String() string {
+	if a.IsNative() {
+		return "XLM"
+	}
+
+	return fmt.Sprintf("%s:%s", a.Code, a.Issuer)
+}
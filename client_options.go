@@ -0,0 +1,24 @@
+package microstellar
+
+// HorizonClient is the subset of Horizon's client surface that
+// MicroStellar depends on to load accounts and submit transactions. It
+// exists so callers — chiefly the conformance vector runner — can
+// substitute a fake in place of the real network client.
+type HorizonClient interface {
+	LoadAccount(address string) ([]byte, error)
+	SubmitTransaction(envelopeXDR string) ([]byte, error)
+	LoadFeeStats() ([]byte, error)
+}
+
+// ClientOption customizes a MicroStellar instance created via New.
+type ClientOption func(*MicroStellar)
+
+// WithHorizonClient overrides the Horizon client a MicroStellar instance
+// talks to. It's primarily useful for tests and the conformance vector
+// runner, which replay recorded Horizon responses instead of hitting the
+// network.
+func WithHorizonClient(hc HorizonClient) ClientOption {
+	return func(ms *MicroStellar) {
+		ms.client = hc
+	}
+}
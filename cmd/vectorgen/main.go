@@ -0,0 +1,167 @@
+// Command vectorgen drives a MicroStellar call against a live Horizon
+// instance (normally the testnet, same as TestMicroStellarEndToEnd) and
+// freezes what it observed into a vector file under
+// conformance/testdata/vectors/, so future refactors can be replayed
+// offline via vectors.Runner.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"path/filepath"
+
+	"github.com/0xfe/microstellar"
+	"github.com/0xfe/microstellar/conformance/vectors"
+)
+
+func main() {
+	var (
+		network    = flag.String("network", "test", "network passphrase to run against, as passed to microstellar.New")
+		horizonURL = flag.String("horizon", "https://horizon-testnet.stellar.org", "Horizon base URL to capture the run against")
+		call       = flag.String("call", "", "MicroStellar call to capture, e.g. Pay, CreateTrustLine")
+		args       = flag.String("args", "{}", "JSON-encoded args for the call, matching vectors.opArgs")
+		name       = flag.String("name", "", "vector name; also used as the output file's base name")
+		outDir     = flag.String("out", "conformance/testdata/vectors", "directory to write the vector into")
+	)
+	flag.Parse()
+
+	if *call == "" || *name == "" {
+		log.Fatal("vectorgen: -call and -name are required")
+	}
+
+	v, err := capture(*horizonURL, *network, *call, *name, []byte(*args))
+	if err != nil {
+		log.Fatalf("vectorgen: %v", err)
+	}
+
+	out, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		log.Fatalf("vectorgen: encoding vector: %v", err)
+	}
+
+	path := filepath.Join(*outDir, *name+".json")
+	if err := ioutil.WriteFile(path, out, 0644); err != nil {
+		log.Fatalf("vectorgen: writing %s: %v", path, err)
+	}
+
+	fmt.Printf("wrote %s\n", path)
+}
+
+// capture wires a recording Horizon client in front of a real one,
+// drives call live against horizonURL, and freezes whatever the
+// recorder saw (the accounts it loaded, the envelope it submitted, and
+// the call's outcome) into a Vector.
+func capture(horizonURL, network, call, name string, rawArgs json.RawMessage) (*vectors.Vector, error) {
+	rec := vectors.NewRecorder(&httpHorizonClient{baseURL: horizonURL, client: http.DefaultClient})
+	ms := microstellar.New(network, microstellar.WithHorizonClient(rec))
+
+	dispatchErr := vectors.Dispatch(ms, call, rawArgs)
+
+	v, err := rec.Vector(name, call, rawArgs)
+	if err != nil {
+		return nil, fmt.Errorf("freezing vector: %v", err)
+	}
+
+	if dispatchErr != nil {
+		v.Expect.ErrorClass = errorClassFromHorizonResponse(v.HorizonResponse)
+		if v.Expect.ErrorClass == "" {
+			// Not a Horizon rejection (e.g. a network error) — fall back to
+			// the full wrapped error, same as before.
+			v.Expect.ErrorClass = microstellar.ErrorString(dispatchErr)
+		}
+	}
+
+	return v, nil
+}
+
+// horizonFailureResponse is the subset of Horizon's failed-submission body
+// this package knows how to classify: the transaction-level result code,
+// and the per-operation codes that caused the rejection.
+type horizonFailureResponse struct {
+	Extras struct {
+		ResultCodes struct {
+			Transaction string   `json:"transaction"`
+			Operations  []string `json:"operations"`
+		} `json:"result_codes"`
+	} `json:"extras"`
+}
+
+// errorClassFromHorizonResponse parses Horizon's result_codes out of a
+// failed submission response, returning the first operation code (e.g.
+// "op_underfunded") or, if none, the transaction-level code (e.g.
+// "tx_bad_seq"). It returns "" if raw isn't a Horizon failure body Go can
+// parse result_codes out of.
+func errorClassFromHorizonResponse(raw json.RawMessage) string {
+	var resp horizonFailureResponse
+	if err := json.Unmarshal(raw, &resp); err != nil {
+		return ""
+	}
+
+	if len(resp.Extras.ResultCodes.Operations) > 0 && resp.Extras.ResultCodes.Operations[0] != "" {
+		return resp.Extras.ResultCodes.Operations[0]
+	}
+
+	return resp.Extras.ResultCodes.Transaction
+}
+
+// httpHorizonClient is a minimal, direct implementation of
+// microstellar.HorizonClient against a live Horizon instance. It exists
+// solely so vectorgen can capture real responses through the same
+// Recorder seam the conformance Runner replays against; it is not meant
+// to replace microstellar's own internal client.
+type httpHorizonClient struct {
+	baseURL string
+	client  *http.Client
+}
+
+func (c *httpHorizonClient) LoadAccount(address string) ([]byte, error) {
+	return c.get("/accounts/" + address)
+}
+
+func (c *httpHorizonClient) SubmitTransaction(envelopeXDR string) ([]byte, error) {
+	resp, err := c.client.PostForm(c.baseURL+"/transactions", url.Values{"tx": {envelopeXDR}})
+	if err != nil {
+		return nil, fmt.Errorf("vectorgen: POST /transactions: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("vectorgen: reading /transactions response: %v", err)
+	}
+	if resp.StatusCode >= 300 {
+		// Return body alongside the error (not just nil) so capture can
+		// parse Horizon's result_codes out of it for the vector's
+		// ErrorClass.
+		return body, fmt.Errorf("vectorgen: POST /transactions: %s: %s", resp.Status, body)
+	}
+
+	return body, nil
+}
+
+func (c *httpHorizonClient) LoadFeeStats() ([]byte, error) {
+	return c.get("/fee_stats")
+}
+
+func (c *httpHorizonClient) get(path string) ([]byte, error) {
+	resp, err := c.client.Get(c.baseURL + path)
+	if err != nil {
+		return nil, fmt.Errorf("vectorgen: GET %s: %v", path, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("vectorgen: reading %s response: %v", path, err)
+	}
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("vectorgen: GET %s: %s: %s", path, resp.Status, body)
+	}
+
+	return body, nil
+}
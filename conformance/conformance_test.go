@@ -0,0 +1,56 @@
+// Package conformance replays recorded microstellar call vectors against
+// an in-memory fake Horizon, so the flows exercised by
+// TestMicroStellarEndToEnd (create account, trustline, pay, add/remove
+// signer, set thresholds, multi-sig pay) can be regression-tested
+// without a live testnet.
+package conformance
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/0xfe/microstellar/conformance/vectors"
+)
+
+const vectorDir = "testdata/vectors"
+
+func TestVectors(t *testing.T) {
+	vs, err := vectors.LoadDir(vectorDir)
+	if err != nil {
+		t.Fatalf("loading vectors: %v", err)
+	}
+
+	if len(vs) == 0 {
+		t.Skipf("no vectors found under %s", vectorDir)
+	}
+
+	runner := vectors.NewRunner("test")
+
+	for _, v := range vs {
+		v := v
+		t.Run(v.Name, func(t *testing.T) {
+			result, err := runner.Run(v)
+			if err != nil {
+				t.Fatalf("running vector: %v", err)
+			}
+
+			if v.Expect.ErrorClass != "" {
+				if result.Err == nil {
+					t.Fatalf("expected error class %q, call succeeded", v.Expect.ErrorClass)
+				}
+				if !strings.Contains(result.Err.Error(), v.Expect.ErrorClass) {
+					t.Fatalf("got error %q, want it to contain error class %q", result.Err, v.Expect.ErrorClass)
+				}
+				return
+			}
+
+			if result.Err != nil {
+				t.Fatalf("unexpected error: %v", result.Err)
+			}
+
+			if result.EnvelopeXDR != v.Expect.EnvelopeXDR {
+				t.Errorf("envelope mismatch:\n got:  %s\n want: %s", result.EnvelopeXDR, v.Expect.EnvelopeXDR)
+			}
+		})
+	}
+}
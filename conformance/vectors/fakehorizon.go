@@ -0,0 +1,93 @@
+package vectors
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// FakeHorizon is an in-memory stand-in for microstellar.HorizonClient.
+// Account-load responses are keyed by a request signature ("GET
+// /accounts/GABC...") so a vector can script exactly what Horizon said
+// without a network round trip; the last submitted envelope is captured
+// so a Runner can hand it back for comparison against a vector's
+// expectation.
+type FakeHorizon struct {
+	responses map[string]json.RawMessage
+	seen      []string
+	lastEnv   string
+}
+
+// NewFakeHorizon builds a FakeHorizon seeded with the given
+// signature->response map, as recorded on a Vector.
+func NewFakeHorizon(responses map[string]json.RawMessage) *FakeHorizon {
+	return &FakeHorizon{responses: responses}
+}
+
+// LoadAccount implements microstellar.HorizonClient by returning the
+// canned response for this address, recording the call so a Runner can
+// assert on call order afterwards.
+func (f *FakeHorizon) LoadAccount(address string) ([]byte, error) {
+	signature := "GET /accounts/" + address
+	f.seen = append(f.seen, signature)
+
+	resp, ok := f.responses[signature]
+	if !ok {
+		resp, ok = f.responses["*"]
+	}
+	if !ok {
+		return nil, fmt.Errorf("vectors: fake horizon has no response for %q", signature)
+	}
+
+	return []byte(resp), nil
+}
+
+// SubmitTransaction implements microstellar.HorizonClient by recording
+// envelopeXDR instead of broadcasting it, then replaying the canned
+// submit response (if any). A canned response carrying an HTTP-style
+// "status" of 300 or higher is treated the way the real Horizon client
+// treats a non-2xx response: as an error, not a successful submission —
+// this is what lets a vector assert a call should fail.
+func (f *FakeHorizon) SubmitTransaction(envelopeXDR string) ([]byte, error) {
+	f.seen = append(f.seen, "POST /transactions")
+	f.lastEnv = envelopeXDR
+
+	resp, ok := f.responses["POST /transactions"]
+	if !ok {
+		return []byte(`{}`), nil
+	}
+
+	var probe struct {
+		Status int `json:"status"`
+	}
+	if err := json.Unmarshal(resp, &probe); err == nil && probe.Status >= 300 {
+		return nil, fmt.Errorf("vectors: fake horizon: POST /transactions: status %d: %s", probe.Status, resp)
+	}
+
+	return []byte(resp), nil
+}
+
+// LoadFeeStats implements microstellar.HorizonClient by returning the
+// canned /fee_stats response, if the vector scripted one.
+func (f *FakeHorizon) LoadFeeStats() ([]byte, error) {
+	signature := "GET /fee_stats"
+	f.seen = append(f.seen, signature)
+
+	resp, ok := f.responses[signature]
+	if !ok {
+		return nil, fmt.Errorf("vectors: fake horizon has no response for %q", signature)
+	}
+
+	return []byte(resp), nil
+}
+
+// LastEnvelope returns the envelope XDR passed to the most recent
+// SubmitTransaction call, or "" if none happened yet.
+func (f *FakeHorizon) LastEnvelope() string {
+	return f.lastEnv
+}
+
+// Calls returns the signatures seen by LoadAccount, SubmitTransaction and
+// LoadFeeStats, in call order.
+func (f *FakeHorizon) Calls() []string {
+	return f.seen
+}
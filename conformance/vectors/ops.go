@@ -0,0 +1,70 @@
+package vectors
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/0xfe/microstellar"
+)
+
+// opArgs is the recorded argument shape shared by every op this runner
+// knows how to dispatch. Not every field applies to every call; unused
+// fields are left zero.
+type opArgs struct {
+	Source        string   `json:"source"`
+	Target        string   `json:"target"`
+	Amount        string   `json:"amount"`
+	AssetCode     string   `json:"assetCode"`
+	AssetIssuer   string   `json:"assetIssuer"`
+	Weight        uint32   `json:"weight"`
+	LowThreshold  uint32   `json:"lowThreshold"`
+	MedThreshold  uint32   `json:"medThreshold"`
+	HighThreshold uint32   `json:"highThreshold"`
+	MemoText      string   `json:"memoText"`
+	SignerSeeds   []string `json:"signerSeeds"`
+}
+
+func dispatchOp(ms *microstellar.MicroStellar, v *Vector) error {
+	return Dispatch(ms, v.Call, v.Args)
+}
+
+// Dispatch invokes the MicroStellar method named by call with the given
+// JSON-encoded args. It's shared by Runner (replaying frozen vectors)
+// and vectorgen (capturing new ones against a live network), so both
+// agree on how a vector's args map onto MicroStellar calls.
+func Dispatch(ms *microstellar.MicroStellar, call string, rawArgs json.RawMessage) error {
+	var args opArgs
+	if err := json.Unmarshal(rawArgs, &args); err != nil {
+		return fmt.Errorf("vectors: decoding args for %q: %v", call, err)
+	}
+
+	opts := microstellar.Opts()
+	if args.MemoText != "" {
+		opts = opts.WithMemoText(args.MemoText)
+	}
+	for _, seed := range args.SignerSeeds {
+		opts = opts.WithSigner(seed)
+	}
+
+	switch call {
+	case "Pay":
+		asset := microstellar.NewAsset(args.AssetCode, args.AssetIssuer, microstellar.Credit4Type)
+		return ms.Pay(args.Source, args.Target, args.Amount, asset, opts)
+	case "PayNative":
+		return ms.PayNative(args.Source, args.Target, args.Amount, opts)
+	case "CreateTrustLine":
+		asset := microstellar.NewAsset(args.AssetCode, args.AssetIssuer, microstellar.Credit4Type)
+		return ms.CreateTrustLine(args.Source, asset, args.Amount)
+	case "RemoveTrustLine":
+		asset := microstellar.NewAsset(args.AssetCode, args.AssetIssuer, microstellar.Credit4Type)
+		return ms.RemoveTrustLine(args.Source, asset)
+	case "AddSigner":
+		return ms.AddSigner(args.Source, args.Target, args.Weight)
+	case "SetMasterWeight":
+		return ms.SetMasterWeight(args.Source, args.Weight)
+	case "SetThresholds":
+		return ms.SetThresholds(args.Source, args.LowThreshold, args.MedThreshold, args.HighThreshold, opts)
+	default:
+		return fmt.Errorf("vectors: dispatchOp called with unhandled call %q", call)
+	}
+}
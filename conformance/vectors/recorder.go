@@ -0,0 +1,139 @@
+package vectors
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/0xfe/microstellar"
+)
+
+// Recorder wraps a real microstellar.HorizonClient, capturing every
+// account it loads and the last transaction it submits. vectorgen wires
+// a Recorder in place of the normal client so a live call can be frozen
+// into a Vector afterwards.
+type Recorder struct {
+	client microstellar.HorizonClient
+
+	accounts   map[string]json.RawMessage // address -> raw Horizon account response
+	loadOrder  []string
+	lastEnv    string
+	submitResp json.RawMessage
+}
+
+// NewRecorder builds a Recorder that delegates to client and remembers
+// everything it saw.
+func NewRecorder(client microstellar.HorizonClient) *Recorder {
+	return &Recorder{client: client, accounts: map[string]json.RawMessage{}}
+}
+
+// LoadAccount implements microstellar.HorizonClient.
+func (r *Recorder) LoadAccount(address string) ([]byte, error) {
+	resp, err := r.client.LoadAccount(address)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, seen := r.accounts[address]; !seen {
+		r.loadOrder = append(r.loadOrder, address)
+	}
+	r.accounts[address] = json.RawMessage(resp)
+
+	return resp, nil
+}
+
+// SubmitTransaction implements microstellar.HorizonClient. The response
+// body is frozen as submitResp whether or not the submission succeeded —
+// a failure response still carries Horizon's result_codes, which capture
+// needs to classify the error — but lastEnv (the envelope a vector
+// expects back from a successful replay) is only set on success.
+func (r *Recorder) SubmitTransaction(envelopeXDR string) ([]byte, error) {
+	resp, err := r.client.SubmitTransaction(envelopeXDR)
+	if err == nil {
+		r.lastEnv = envelopeXDR
+	}
+	if len(resp) > 0 {
+		r.submitResp = json.RawMessage(resp)
+	}
+
+	return resp, err
+}
+
+// LoadFeeStats implements microstellar.HorizonClient.
+func (r *Recorder) LoadFeeStats() ([]byte, error) {
+	return r.client.LoadFeeStats()
+}
+
+// Vector freezes everything Recorder captured while driving a single
+// call into a replayable Vector. It's an error to call Vector before any
+// account has been loaded, since every dispatched call loads its source
+// account first.
+func (r *Recorder) Vector(name, call string, args json.RawMessage) (*Vector, error) {
+	if len(r.loadOrder) == 0 {
+		return nil, fmt.Errorf("vectors: Recorder.Vector: no accounts were loaded, nothing to freeze")
+	}
+
+	pre := PreState{Accounts: map[string]Account{}}
+	for _, address := range r.loadOrder {
+		account, err := parseHorizonAccount(r.accounts[address])
+		if err != nil {
+			return nil, fmt.Errorf("vectors: parsing captured account %s: %v", address, err)
+		}
+		pre.Accounts[address] = *account
+	}
+
+	return &Vector{
+		Name:            name,
+		Call:            call,
+		Args:            args,
+		PreState:        pre,
+		HorizonResponse: r.submitResp,
+		Expect: Expectation{
+			EnvelopeXDR: r.lastEnv,
+		},
+	}, nil
+}
+
+// horizonAccount is the subset of Horizon's account-load response this
+// package knows how to freeze into a PreState Account.
+type horizonAccount struct {
+	Sequence string `json:"sequence"`
+	Balances []struct {
+		AssetType string `json:"asset_type"`
+		AssetCode string `json:"asset_code"`
+		Issuer    string `json:"asset_issuer"`
+		Balance   string `json:"balance"`
+	} `json:"balances"`
+	Signers []struct {
+		Key    string `json:"key"`
+		Weight uint32 `json:"weight"`
+	} `json:"signers"`
+}
+
+func parseHorizonAccount(raw json.RawMessage) (*Account, error) {
+	var ha horizonAccount
+	if err := json.Unmarshal(raw, &ha); err != nil {
+		return nil, err
+	}
+
+	account := &Account{
+		Sequence: ha.Sequence,
+		Balances: map[string]string{},
+	}
+
+	for _, b := range ha.Balances {
+		key := "native"
+		if b.AssetType != "native" {
+			key = b.AssetCode + ":" + b.Issuer
+		}
+		account.Balances[key] = b.Balance
+	}
+
+	for _, s := range ha.Signers {
+		account.Signers = append(account.Signers, AccountSigner{
+			Key:    s.Key,
+			Weight: s.Weight,
+		})
+	}
+
+	return account, nil
+}
@@ -0,0 +1,133 @@
+package microstellar
+
+import (
+	"fmt"
+
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/txnbuild"
+)
+
+// baseFee is the minimum per-operation fee (in stroops) the network will
+// accept; it's the floor a transaction is built with absent a
+// WithFeeStrategy override.
+const baseFee = 100
+
+// toTxnBuildAsset converts an Asset into the txnbuild representation
+// operations build against.
+func (a *Asset) toTxnBuildAsset() (txnbuild.Asset, error) {
+	if a.IsNative() {
+		return txnbuild.NativeAsset{}, nil
+	}
+
+	switch a.Type {
+	case Credit4Type, Credit12Type:
+		return txnbuild.CreditAsset{Code: a.Code, Issuer: a.Issuer}, nil
+	default:
+		return nil, fmt.Errorf("unknown asset type %v", a.Type)
+	}
+}
+
+// resolveSigners splits source into the account the transaction is sent
+// from and the keypairs that should sign it: source itself (if it's a
+// seed) plus any cosigners from opts.WithSigner.
+func resolveSigners(source string, opts *Options) (sourceAddress string, signers []*keypair.Full, err error) {
+	kp, err := keypair.Parse(source)
+	if err != nil {
+		return "", nil, fmt.Errorf("parsing source %q: %v", source, err)
+	}
+
+	sourceAddress = kp.Address()
+
+	if full, ok := kp.(*keypair.Full); ok {
+		signers = append(signers, full)
+	}
+
+	for _, seed := range opts.SignerSeeds() {
+		full, err := keypair.ParseFull(seed)
+		if err != nil {
+			return "", nil, fmt.Errorf("parsing signer seed: %v", err)
+		}
+		signers = append(signers, full)
+	}
+
+	return sourceAddress, signers, nil
+}
+
+// buildSignSubmit builds a transaction running ops from source, signs it
+// with source (if it's a seed) and any cosigners from opts, submits it
+// to Horizon, and returns the raw submission response.
+func (ms *MicroStellar) buildSignSubmit(source string, ops []txnbuild.Operation, opts *Options) ([]byte, error) {
+	env, err := ms.buildSignedEnvelope(source, ops, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := ms.client.SubmitTransaction(env)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// buildSignedEnvelope builds and signs a transaction running ops from
+// source, applying opts' memo, cosigners and fee strategy, and returns
+// it as a base64 XDR envelope ready to submit or fee-bump.
+func (ms *MicroStellar) buildSignedEnvelope(source string, ops []txnbuild.Operation, opts *Options) (string, error) {
+	sourceAddress, signers, err := resolveSigners(source, opts)
+	if err != nil {
+		return "", fmt.Errorf("resolving signers: %v", err)
+	}
+
+	account, err := ms.LoadAccount(sourceAddress)
+	if err != nil {
+		return "", fmt.Errorf("loading source account: %v", err)
+	}
+
+	fee, err := ms.resolveBaseFee(opts)
+	if err != nil {
+		return "", fmt.Errorf("resolving fee: %v", err)
+	}
+
+	params := txnbuild.TransactionParams{
+		SourceAccount:        &txnbuild.SimpleAccount{AccountID: sourceAddress, Sequence: int64(account.Sequence())},
+		IncrementSequenceNum: true,
+		Operations:           ops,
+		BaseFee:              fee,
+		Preconditions:        txnbuild.Preconditions{TimeBounds: txnbuild.NewInfiniteTimeout()},
+	}
+
+	if memo := opts.MemoText(); memo != "" {
+		params.Memo = txnbuild.MemoText(memo)
+	}
+
+	tx, err := txnbuild.NewTransaction(params)
+	if err != nil {
+		return "", fmt.Errorf("building transaction: %v", err)
+	}
+
+	tx, err = tx.Sign(networkPassphraseFor(ms.networkName), signers...)
+	if err != nil {
+		return "", fmt.Errorf("signing transaction: %v", err)
+	}
+
+	return tx.Base64()
+}
+
+// resolveBaseFee picks the per-operation base fee a transaction should
+// be built with: opts' fee strategy applied against live Horizon fee
+// stats if one was set via WithFeeStrategy, or the network minimum
+// otherwise.
+func (ms *MicroStellar) resolveBaseFee(opts *Options) (int64, error) {
+	strategy := opts.FeeStrategyOrDefault(nil)
+	if strategy == nil {
+		return baseFee, nil
+	}
+
+	stats, err := ms.loadFeeStats()
+	if err != nil {
+		return 0, fmt.Errorf("loading fee stats for strategy: %v", err)
+	}
+
+	return int64(strategy(stats)), nil
+}
@@ -0,0 +1,177 @@
+package microstellar
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/txnbuild"
+)
+
+// FeeStats is a snapshot of Horizon's /fee_stats response: the ledger's
+// current base fee plus the distribution of per-operation fees paid by
+// recently included transactions.
+type FeeStats struct {
+	LedgerBaseFee uint64
+	Min           uint64
+	Mode          uint64
+	P50           uint64
+	P90           uint64
+	P99           uint64
+	// Recommended is LedgerBaseFee/Min/Mode/P50/P90/P99 run through the
+	// FeeStrategy passed to EstimateFee (FeePercentile(50) by default).
+	Recommended uint64
+}
+
+// FeeStrategy picks a per-operation fee (in stroops) given the latest
+// FeeStats, so callers can bid above the network minimum on congested
+// networks. Set one with Options.WithFeeStrategy.
+type FeeStrategy func(stats *FeeStats) uint64
+
+// FeeFixed always bids exactly n stroops per operation, ignoring stats.
+func FeeFixed(n uint64) FeeStrategy {
+	return func(stats *FeeStats) uint64 {
+		return n
+	}
+}
+
+// FeeMultiplier bids x times the ledger's current base fee.
+func FeeMultiplier(x float64) FeeStrategy {
+	return func(stats *FeeStats) uint64 {
+		return uint64(float64(stats.LedgerBaseFee) * x)
+	}
+}
+
+// FeePercentile bids the fee paid at the given percentile of recent
+// transactions. p must be one of 50, 90 or 99; anything else falls back
+// to FeeStats.Mode.
+func FeePercentile(p int) FeeStrategy {
+	return func(stats *FeeStats) uint64 {
+		switch p {
+		case 50:
+			return stats.P50
+		case 90:
+			return stats.P90
+		case 99:
+			return stats.P99
+		default:
+			return stats.Mode
+		}
+	}
+}
+
+// FeeCap wraps strategy so it never bids above max, the same way a
+// max-fee ceiling on a hot account keeps a runaway estimate from
+// draining it.
+func FeeCap(strategy FeeStrategy, max uint64) FeeStrategy {
+	return func(stats *FeeStats) uint64 {
+		fee := strategy(stats)
+		if fee > max {
+			return max
+		}
+		return fee
+	}
+}
+
+// EstimateFee pulls /fee_stats from Horizon and returns the ledger's
+// current base fee, the min/mode/p50/p90/p99 per-operation fee paid by
+// recently included transactions, and a Recommended fee computed by
+// running those stats through opts' FeeStrategy (FeePercentile(50) if
+// opts is nil or sets none via WithFeeStrategy).
+func (ms *MicroStellar) EstimateFee(opts *Options) (*FeeStats, error) {
+	stats, err := ms.loadFeeStats()
+	if err != nil {
+		return nil, fmt.Errorf("microstellar: EstimateFee: %v", err)
+	}
+
+	strategy := opts.FeeStrategyOrDefault(FeePercentile(50))
+	stats.Recommended = strategy(stats)
+
+	return stats, nil
+}
+
+type horizonFeeStatsResponse struct {
+	LastLedgerBaseFee string `json:"last_ledger_base_fee"`
+	FeeCharged        struct {
+		Min  string `json:"min"`
+		Mode string `json:"mode"`
+		P50  string `json:"p50"`
+		P90  string `json:"p90"`
+		P99  string `json:"p99"`
+	} `json:"fee_charged"`
+}
+
+// loadFeeStats fetches and parses Horizon's /fee_stats response.
+func (ms *MicroStellar) loadFeeStats() (*FeeStats, error) {
+	raw, err := ms.client.LoadFeeStats()
+	if err != nil {
+		return nil, fmt.Errorf("loading /fee_stats: %v", err)
+	}
+
+	var resp horizonFeeStatsResponse
+	if err := json.Unmarshal(raw, &resp); err != nil {
+		return nil, fmt.Errorf("parsing /fee_stats response: %v", err)
+	}
+
+	return &FeeStats{
+		LedgerBaseFee: parseFeeAmount(resp.LastLedgerBaseFee),
+		Min:           parseFeeAmount(resp.FeeCharged.Min),
+		Mode:          parseFeeAmount(resp.FeeCharged.Mode),
+		P50:           parseFeeAmount(resp.FeeCharged.P50),
+		P90:           parseFeeAmount(resp.FeeCharged.P90),
+		P99:           parseFeeAmount(resp.FeeCharged.P99),
+	}, nil
+}
+
+func parseFeeAmount(s string) uint64 {
+	n, _ := strconv.ParseUint(s, 10, 64)
+	return n
+}
+
+// BumpFee wraps the signed transaction in innerTxB64 in a CAP-0015
+// fee-bump envelope paid for by feeSourceSeed at newBaseFee per
+// operation, and submits it, without requiring the inner transaction's
+// original signers to re-sign anything.
+func (ms *MicroStellar) BumpFee(innerTxB64 string, feeSourceSeed string, newBaseFee uint64) (string, error) {
+	generic, err := txnbuild.TransactionFromXDR(innerTxB64)
+	if err != nil {
+		return "", fmt.Errorf("microstellar: BumpFee: decoding inner tx: %v", err)
+	}
+
+	inner, ok := generic.Transaction()
+	if !ok {
+		return "", fmt.Errorf("microstellar: BumpFee: inner envelope is already a fee-bump transaction")
+	}
+
+	feeSource, err := keypair.ParseFull(feeSourceSeed)
+	if err != nil {
+		return "", fmt.Errorf("microstellar: BumpFee: parsing fee source seed: %v", err)
+	}
+
+	feeBump, err := txnbuild.NewFeeBumpTransaction(txnbuild.FeeBumpTransactionParams{
+		Inner:      inner,
+		FeeAccount: feeSource.Address(),
+		BaseFee:    int64(newBaseFee),
+	})
+	if err != nil {
+		return "", fmt.Errorf("microstellar: BumpFee: building fee-bump envelope: %v", err)
+	}
+
+	feeBump, err = feeBump.Sign(networkPassphraseFor(ms.networkName), feeSource)
+	if err != nil {
+		return "", fmt.Errorf("microstellar: BumpFee: signing fee-bump envelope: %v", err)
+	}
+
+	envB64, err := feeBump.Base64()
+	if err != nil {
+		return "", fmt.Errorf("microstellar: BumpFee: encoding fee-bump envelope: %v", err)
+	}
+
+	resp, err := ms.client.SubmitTransaction(envB64)
+	if err != nil {
+		return "", fmt.Errorf("microstellar: BumpFee: submitting: %v", err)
+	}
+
+	return string(resp), nil
+}
@@ -0,0 +1,141 @@
+package microstellar
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stellar/go/keypair"
+)
+
+// fakeFeesHorizon is a minimal HorizonClient that serves a canned
+// /fee_stats response and a single account, for exercising EstimateFee
+// and BumpFee without a network.
+type fakeFeesHorizon struct {
+	feeStats  string
+	accounts  map[string]string // address -> raw Horizon account JSON
+	submitted string            // last envelope handed to SubmitTransaction
+}
+
+func (f *fakeFeesHorizon) LoadAccount(address string) ([]byte, error) {
+	raw, ok := f.accounts[address]
+	if !ok {
+		return nil, fmt.Errorf("fakeFeesHorizon: no account for %s", address)
+	}
+	return []byte(raw), nil
+}
+
+func (f *fakeFeesHorizon) SubmitTransaction(envelopeXDR string) ([]byte, error) {
+	f.submitted = envelopeXDR
+	return []byte(`{}`), nil
+}
+
+func (f *fakeFeesHorizon) LoadFeeStats() ([]byte, error) {
+	return []byte(f.feeStats), nil
+}
+
+const feeStatsJSON = `{
+	"last_ledger_base_fee": "100",
+	"fee_charged": {"min":"100","mode":"150","p50":"150","p90":"300","p99":"1000"}
+}`
+
+func TestEstimateFeeDefaultStrategy(t *testing.T) {
+	ms := New("test", WithHorizonClient(&fakeFeesHorizon{feeStats: feeStatsJSON}))
+
+	stats, err := ms.EstimateFee(nil)
+	if err != nil {
+		t.Fatalf("EstimateFee: %v", err)
+	}
+
+	if stats.LedgerBaseFee != 100 || stats.P90 != 300 {
+		t.Fatalf("got stats %+v, want LedgerBaseFee=100, P90=300", stats)
+	}
+	if stats.Recommended != stats.P50 {
+		t.Fatalf("got Recommended %d, want default FeePercentile(50) of %d", stats.Recommended, stats.P50)
+	}
+}
+
+func TestEstimateFeeWithStrategy(t *testing.T) {
+	ms := New("test", WithHorizonClient(&fakeFeesHorizon{feeStats: feeStatsJSON}))
+
+	stats, err := ms.EstimateFee(Opts().WithFeeStrategy(FeePercentile(90)))
+	if err != nil {
+		t.Fatalf("EstimateFee: %v", err)
+	}
+
+	if stats.Recommended != stats.P90 {
+		t.Fatalf("got Recommended %d, want P90 %d", stats.Recommended, stats.P90)
+	}
+}
+
+func TestFeeFixed(t *testing.T) {
+	strategy := FeeFixed(250)
+	if got := strategy(&FeeStats{LedgerBaseFee: 100, P90: 300}); got != 250 {
+		t.Fatalf("got %d, want 250", got)
+	}
+}
+
+func TestFeeMultiplier(t *testing.T) {
+	strategy := FeeMultiplier(2.5)
+	if got := strategy(&FeeStats{LedgerBaseFee: 100}); got != 250 {
+		t.Fatalf("got %d, want 250", got)
+	}
+}
+
+func TestFeePercentileFallsBackToMode(t *testing.T) {
+	strategy := FeePercentile(42)
+	if got := strategy(&FeeStats{Mode: 175}); got != 175 {
+		t.Fatalf("got %d, want Mode 175 for an unrecognized percentile", got)
+	}
+}
+
+func TestFeeCap(t *testing.T) {
+	capped := FeeCap(FeeFixed(500), 200)
+	if got := capped(&FeeStats{}); got != 200 {
+		t.Fatalf("got %d, want capped to 200", got)
+	}
+
+	uncapped := FeeCap(FeeFixed(100), 200)
+	if got := uncapped(&FeeStats{}); got != 100 {
+		t.Fatalf("got %d, want 100 (below the cap)", got)
+	}
+}
+
+func TestBumpFee(t *testing.T) {
+	sourceKP, err := keypair.Random()
+	if err != nil {
+		t.Fatalf("keypair.Random: %v", err)
+	}
+	destKP, err := keypair.Random()
+	if err != nil {
+		t.Fatalf("keypair.Random: %v", err)
+	}
+	feeSourceKP, err := keypair.Random()
+	if err != nil {
+		t.Fatalf("keypair.Random: %v", err)
+	}
+
+	fake := &fakeFeesHorizon{
+		feeStats: feeStatsJSON,
+		accounts: map[string]string{
+			sourceKP.Address(): accountJSON("100", 1, 0, 0, 0),
+		},
+	}
+	ms := New("test", WithHorizonClient(fake))
+
+	stuckTxB64, err := ms.SignTx(sourceKP.Seed(), []Op{PaymentOp(destKP.Address(), "10", nil)},
+		Opts().WithFeeStrategy(FeeFixed(100)))
+	if err != nil {
+		t.Fatalf("SignTx: %v", err)
+	}
+
+	if _, err := ms.BumpFee(stuckTxB64, feeSourceKP.Seed(), 1000); err != nil {
+		t.Fatalf("BumpFee: %v", err)
+	}
+
+	if fake.submitted == "" {
+		t.Fatal("BumpFee didn't submit the fee-bump envelope")
+	}
+	if fake.submitted == stuckTxB64 {
+		t.Fatal("BumpFee submitted the original envelope instead of a fee-bump wrapping it")
+	}
+}
@@ -56,7 +56,7 @@ func createFundedAccount(ms *microstellar.MicroStellar, fundSourceSeed string, u
 		err := ms.PayNative(keyPair.Seed, fundSourceSeed, "5000", microstellar.Opts().WithMemoText("friendbot payback"))
 
 		if err != nil {
-			log.Fatalf(microstellar.ErrorString(err))
+			log.Fatalf("PayNative: %v", microstellar.ErrorString(err))
 		}
 	}
 
@@ -81,6 +81,29 @@ func showBalance(ms *microstellar.MicroStellar, asset *microstellar.Asset, name,
 	}
 }
 
+// simulateAndPay runs SimulateTx against the payment before sending it for
+// real, then asserts that its Verdict agreed with whether the submission
+// actually succeeded — replacing a blind "if it failed, assume that was
+// expected" guess with an assertion against the library's own prediction.
+func simulateAndPay(ms *microstellar.MicroStellar, source, target, amount string, asset *microstellar.Asset, opts *microstellar.Options) {
+	sim, err := ms.SimulateTx(source, []microstellar.Op{microstellar.PaymentOp(target, amount, asset)}, opts)
+	if err != nil {
+		log.Fatalf("SimulateTx: %v", microstellar.ErrorString(err))
+	}
+	log.Printf("SimulateTx verdict: %v (%s)", sim.Verdict, sim.Reason)
+
+	err = ms.Pay(source, target, amount, asset, opts)
+
+	wantFail := sim.Verdict != microstellar.OK
+	if wantFail && err == nil {
+		log.Fatalf("SimulateTx predicted %v, but the payment succeeded", sim.Verdict)
+	}
+	if !wantFail && err != nil {
+		log.Fatalf("SimulateTx predicted OK, but the payment failed: %v", microstellar.ErrorString(err))
+	}
+	log.Printf("payment outcome matched SimulateTx's %v prediction", sim.Verdict)
+}
+
 // TestMicroStellarEndToEnd implements the full end-to-end test
 func TestMicroStellarEndToEnd(t *testing.T) {
 	const fundSourceSeed = "SBW2N5EK5MZTKPQJZ6UYXEMCA63AO3AVUR6U5CUOIDFYCAR2X2IJIZAX"
@@ -135,27 +158,15 @@ func TestMicroStellarEndToEnd(t *testing.T) {
 	showBalance(ms, USD, "distributor", keyPair2.Address)
 
 	log.Print("Paying USD from distributor to customer (with dead master signer)...")
-	err = ms.Pay(keyPair2.Seed, keyPair3.Address, "5000", USD, microstellar.Opts().WithMemoText("failed payment"))
-
-	if err != nil {
-		log.Print("Payment correctly failed.")
-	} else {
-		log.Fatalf("Payment suceeded. This should not have happened.")
-	}
+	simulateAndPay(ms, keyPair2.Seed, keyPair3.Address, "5000", USD, microstellar.Opts().WithMemoText("failed payment"))
 
 	log.Print("Paying USD from distributor to customer (with too many signers)...")
-	err = ms.Pay(keyPair2.Address, keyPair3.Address, "5000", USD,
+	simulateAndPay(ms, keyPair2.Address, keyPair3.Address, "5000", USD,
 		microstellar.Opts().
 			WithMemoText("real payment").
 			WithSigner(keyPair4.Seed).
 			WithSigner(keyPair5.Seed))
 
-	if err != nil {
-		log.Print("Payment correctly failed (too many signers).")
-	} else {
-		log.Fatalf("Payment succeeded. This should not have happened.")
-	}
-
 	log.Print("Paying USD from distributor to customer (with correct signers)...")
 	err = ms.Pay(keyPair2.Address, keyPair3.Address, "5000", USD,
 		microstellar.Opts().
@@ -184,6 +195,49 @@ func TestMicroStellarEndToEnd(t *testing.T) {
 		log.Fatalf("Payment failed: %v", microstellar.ErrorString(err))
 	}
 
+	log.Print("Estimating fees before a fee-bump retry...")
+	feeStats, err := ms.EstimateFee(microstellar.Opts().WithFeeStrategy(microstellar.FeePercentile(90)))
+
+	if err != nil {
+		log.Fatalf("EstimateFee: %v", microstellar.ErrorString(err))
+	}
+
+	log.Printf("Ledger base fee: %d, recommended (p90): %d", feeStats.LedgerBaseFee, feeStats.Recommended)
+
+	log.Print("Signing a USD payment from distributor to customer with a deliberately low fee...")
+	stuckTxB64, err := ms.SignTx(keyPair2.Address, []microstellar.Op{microstellar.PaymentOp(keyPair3.Address, "1000", USD)},
+		microstellar.Opts().
+			WithMemoText("stuck on a low fee").
+			WithSigner(keyPair4.Seed).
+			WithSigner(keyPair5.Seed).
+			WithFeeStrategy(microstellar.FeeFixed(feeStats.Min)))
+
+	if err != nil {
+		log.Fatalf("SignTx: %v", microstellar.ErrorString(err))
+	}
+
+	log.Print("Decoding the stuck transaction before bumping its fee...")
+	decoded, err := ms.DecodeEnvelope(stuckTxB64)
+
+	if err != nil {
+		log.Fatalf("DecodeEnvelope: %v", microstellar.ErrorString(err))
+	}
+	if decoded.Source != keyPair2.Address {
+		log.Fatalf("decoded source %s, want %s", decoded.Source, keyPair2.Address)
+	}
+	if decoded.Memo != "stuck on a low fee" {
+		log.Fatalf("decoded memo %q, want %q", decoded.Memo, "stuck on a low fee")
+	}
+	log.Printf("Decoded stuck tx: source=%s sequence=%d fee=%d memo=%q signers=%v",
+		decoded.Source, decoded.Sequence, decoded.Fee, decoded.Memo, decoded.Signers)
+
+	log.Print("Bumping the stuck transaction's fee from the issuer account...")
+	_, err = ms.BumpFee(stuckTxB64, keyPair1.Seed, feeStats.Recommended)
+
+	if err != nil {
+		log.Fatalf("BumpFee: %v", microstellar.ErrorString(err))
+	}
+
 	log.Print("Sending back USD from customer to distributor before removing trustline...")
 	err = ms.Pay(keyPair3.Seed, keyPair2.Address, "10000", USD,
 		microstellar.Opts().WithMemoText("take it back"))
@@ -0,0 +1,335 @@
+package microstellar
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/network"
+	"github.com/stellar/go/txnbuild"
+)
+
+// MicroStellar is the main handle applications use to build, sign and
+// submit Stellar transactions. Create one with New.
+type MicroStellar struct {
+	networkName string
+	client      HorizonClient
+}
+
+// New creates a MicroStellar client for the given network name ("test"
+// for the testnet, "public" for the public network), optionally
+// customized via ClientOptions — chiefly WithHorizonClient, which swaps
+// in a fake Horizon client for tests and the conformance vector runner.
+func New(networkName string, opts ...ClientOption) *MicroStellar {
+	ms := &MicroStellar{networkName: networkName}
+
+	for _, opt := range opts {
+		opt(ms)
+	}
+
+	if ms.client == nil {
+		ms.client = &defaultHorizonClient{baseURL: horizonURLFor(networkName), http: http.DefaultClient}
+	}
+
+	return ms
+}
+
+// ErrorString returns a human-readable description of err, or "" if err
+// is nil.
+func ErrorString(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	return err.Error()
+}
+
+// KeyPair is a Stellar address/seed pair.
+type KeyPair struct {
+	Address string
+	Seed    string
+}
+
+// CreateKeyPair generates a new random KeyPair.
+func (ms *MicroStellar) CreateKeyPair() (*KeyPair, error) {
+	kp, err := keypair.Random()
+	if err != nil {
+		return nil, fmt.Errorf("microstellar: CreateKeyPair: %v", err)
+	}
+
+	return &KeyPair{Address: kp.Address(), Seed: kp.Seed()}, nil
+}
+
+// FundWithFriendBot asks testnet friendbot to fund address, returning
+// its response body for logging.
+func FundWithFriendBot(address string) (string, error) {
+	resp, err := http.Get("https://friendbot.stellar.org/?addr=" + url.QueryEscape(address))
+	if err != nil {
+		return "", fmt.Errorf("microstellar: FundWithFriendBot: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("microstellar: FundWithFriendBot: %v", err)
+	}
+
+	return string(body), nil
+}
+
+// LoadAccount fetches address's current state from Horizon: its
+// sequence number, thresholds, signers and balances.
+func (ms *MicroStellar) LoadAccount(address string) (*Account, error) {
+	raw, err := ms.client.LoadAccount(address)
+	if err != nil {
+		return nil, fmt.Errorf("microstellar: LoadAccount: %v", err)
+	}
+
+	return parseAccount(address, raw)
+}
+
+type horizonAccountResponse struct {
+	Sequence string `json:"sequence"`
+	Balances []struct {
+		AssetType string `json:"asset_type"`
+		AssetCode string `json:"asset_code"`
+		Issuer    string `json:"asset_issuer"`
+		Balance   string `json:"balance"`
+	} `json:"balances"`
+	Signers []struct {
+		Type   string `json:"type"`
+		Weight uint32 `json:"weight"`
+		Key    string `json:"key"`
+	} `json:"signers"`
+	Thresholds struct {
+		LowThreshold  uint32 `json:"low_threshold"`
+		MedThreshold  uint32 `json:"med_threshold"`
+		HighThreshold uint32 `json:"high_threshold"`
+	} `json:"thresholds"`
+}
+
+func parseAccount(address string, raw []byte) (*Account, error) {
+	var resp horizonAccountResponse
+	if err := json.Unmarshal(raw, &resp); err != nil {
+		return nil, fmt.Errorf("parsing account response: %v", err)
+	}
+
+	seq, err := strconv.ParseUint(resp.Sequence, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("parsing sequence number %q: %v", resp.Sequence, err)
+	}
+
+	account := &Account{
+		Address:       address,
+		sequence:      seq,
+		lowThreshold:  resp.Thresholds.LowThreshold,
+		medThreshold:  resp.Thresholds.MedThreshold,
+		highThreshold: resp.Thresholds.HighThreshold,
+		balances:      map[string]string{},
+	}
+
+	for _, b := range resp.Balances {
+		if b.AssetType == "native" {
+			account.setBalance(NativeAsset, b.Balance)
+		} else {
+			account.setBalance(NewAsset(b.AssetCode, b.Issuer, Credit4Type), b.Balance)
+		}
+	}
+
+	for _, s := range resp.Signers {
+		account.Signers = append(account.Signers, AccountSigner{Type: s.Type, Weight: s.Weight, PublicKey: s.Key})
+		if s.Key == address {
+			account.masterWeight = s.Weight
+		}
+	}
+
+	return account, nil
+}
+
+// horizonURLFor maps a network name to its Horizon base URL.
+func horizonURLFor(networkName string) string {
+	if networkName == "public" {
+		return "https://horizon.stellar.org"
+	}
+
+	return "https://horizon-testnet.stellar.org"
+}
+
+// networkPassphraseFor maps a network name to the passphrase Stellar
+// transactions are signed against.
+func networkPassphraseFor(networkName string) string {
+	if networkName == "public" {
+		return network.PublicNetworkPassphrase
+	}
+
+	return network.TestNetworkPassphrase
+}
+
+// defaultHorizonClient is the real, network-backed HorizonClient New
+// uses when no ClientOption overrides it.
+type defaultHorizonClient struct {
+	baseURL string
+	http    *http.Client
+}
+
+func (c *defaultHorizonClient) LoadAccount(address string) ([]byte, error) {
+	return c.get("/accounts/" + address)
+}
+
+func (c *defaultHorizonClient) SubmitTransaction(envelopeXDR string) ([]byte, error) {
+	resp, err := c.http.PostForm(c.baseURL+"/transactions", url.Values{"tx": {envelopeXDR}})
+	if err != nil {
+		return nil, fmt.Errorf("POST /transactions: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading /transactions response: %v", err)
+	}
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("POST /transactions: %s: %s", resp.Status, body)
+	}
+
+	return body, nil
+}
+
+func (c *defaultHorizonClient) LoadFeeStats() ([]byte, error) {
+	return c.get("/fee_stats")
+}
+
+func (c *defaultHorizonClient) get(path string) ([]byte, error) {
+	resp, err := c.http.Get(c.baseURL + path)
+	if err != nil {
+		return nil, fmt.Errorf("GET %s: %v", path, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s response: %v", path, err)
+	}
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("GET %s: %s: %s", path, resp.Status, body)
+	}
+
+	return body, nil
+}
+
+// Pay sends amount units of asset from source to target. source may be
+// either a seed (if it's also the only signer) or an address — any
+// additional signers required must be supplied via opts.WithSigner.
+func (ms *MicroStellar) Pay(source, target, amount string, asset *Asset, opts ...*Options) error {
+	xdrAsset, err := asset.toTxnBuildAsset()
+	if err != nil {
+		return fmt.Errorf("microstellar: Pay: %v", err)
+	}
+
+	op := &txnbuild.Payment{Destination: target, Amount: amount, Asset: xdrAsset}
+
+	if _, err := ms.buildSignSubmit(source, []txnbuild.Operation{op}, mergeOptions(opts)); err != nil {
+		return fmt.Errorf("microstellar: Pay: %v", err)
+	}
+
+	return nil
+}
+
+// PayNative sends amount lumens from source to target.
+func (ms *MicroStellar) PayNative(source, target, amount string, opts ...*Options) error {
+	return ms.Pay(source, target, amount, NativeAsset, opts...)
+}
+
+// FundAccount funds a brand-new target account from source with amount
+// lumens (a Stellar "create account" operation).
+func (ms *MicroStellar) FundAccount(source, target, amount string, opts ...*Options) error {
+	op := &txnbuild.CreateAccount{Destination: target, Amount: amount}
+
+	if _, err := ms.buildSignSubmit(source, []txnbuild.Operation{op}, mergeOptions(opts)); err != nil {
+		return fmt.Errorf("microstellar: FundAccount: %v", err)
+	}
+
+	return nil
+}
+
+// CreateTrustLine establishes a trustline from source for up to limit
+// units of asset.
+func (ms *MicroStellar) CreateTrustLine(source string, asset *Asset, limit string) error {
+	return ms.changeTrust(source, asset, limit)
+}
+
+// RemoveTrustLine removes source's trustline for asset.
+func (ms *MicroStellar) RemoveTrustLine(source string, asset *Asset) error {
+	return ms.changeTrust(source, asset, "0")
+}
+
+func (ms *MicroStellar) changeTrust(source string, asset *Asset, limit string) error {
+	xdrAsset, err := asset.toTxnBuildAsset()
+	if err != nil {
+		return fmt.Errorf("microstellar: changeTrust: %v", err)
+	}
+
+	ctAsset, err := xdrAsset.ToChangeTrustAsset()
+	if err != nil {
+		return fmt.Errorf("microstellar: changeTrust: %v", err)
+	}
+
+	op := &txnbuild.ChangeTrust{Line: ctAsset, Limit: limit}
+
+	if _, err := ms.buildSignSubmit(source, []txnbuild.Operation{op}, nil); err != nil {
+		return fmt.Errorf("microstellar: changeTrust: %v", err)
+	}
+
+	return nil
+}
+
+// AddSigner adds signerAddress as a cosigner of source with the given
+// weight.
+func (ms *MicroStellar) AddSigner(source, signerAddress string, weight uint32) error {
+	op := &txnbuild.SetOptions{Signer: &txnbuild.Signer{Address: signerAddress, Weight: txnbuild.Threshold(weight)}}
+
+	if _, err := ms.buildSignSubmit(source, []txnbuild.Operation{op}, nil); err != nil {
+		return fmt.Errorf("microstellar: AddSigner: %v", err)
+	}
+
+	return nil
+}
+
+// SetMasterWeight sets source's own master key signing weight.
+func (ms *MicroStellar) SetMasterWeight(source string, weight uint32) error {
+	w := txnbuild.Threshold(weight)
+	op := &txnbuild.SetOptions{MasterWeight: &w}
+
+	if _, err := ms.buildSignSubmit(source, []txnbuild.Operation{op}, nil); err != nil {
+		return fmt.Errorf("microstellar: SetMasterWeight: %v", err)
+	}
+
+	return nil
+}
+
+// SetThresholds sets source's low/medium/high operation thresholds.
+func (ms *MicroStellar) SetThresholds(source string, low, med, high uint32, opts ...*Options) error {
+	lowT, medT, highT := txnbuild.Threshold(low), txnbuild.Threshold(med), txnbuild.Threshold(high)
+	op := &txnbuild.SetOptions{LowThreshold: &lowT, MediumThreshold: &medT, HighThreshold: &highT}
+
+	if _, err := ms.buildSignSubmit(source, []txnbuild.Operation{op}, mergeOptions(opts)); err != nil {
+		return fmt.Errorf("microstellar: SetThresholds: %v", err)
+	}
+
+	return nil
+}
+
+// mergeOptions collapses a variadic ...*Options call into the single
+// Options buildSignSubmit expects — the exported methods above that take
+// ...*Options do so for convenience at the call site, but only the first
+// is used.
+func mergeOptions(opts []*Options) *Options {
+	if len(opts) == 0 {
+		return nil
+	}
+
+	return opts[0]
+}
@@ -0,0 +1,66 @@
+package microstellar
+
+// Options customizes a transaction-building call (Pay, FundAccount,
+// SetThresholds, SignTx, ...): its memo, any extra cosigners, and the
+// fee it should submit with.
+type Options struct {
+	memoText    string
+	signerSeeds []string
+	feeStrategy FeeStrategy
+}
+
+// Opts returns a new, empty Options ready for chaining.
+func Opts() *Options {
+	return &Options{}
+}
+
+// NewTxOptions is an older name for Opts, kept for existing callers.
+func NewTxOptions() *Options {
+	return Opts()
+}
+
+// WithMemoText attaches a text memo to the transaction.
+func (o *Options) WithMemoText(memo string) *Options {
+	o.memoText = memo
+	return o
+}
+
+// WithSigner adds seed as an extra cosigner: the transaction is signed
+// with it in addition to the source account's key.
+func (o *Options) WithSigner(seed string) *Options {
+	o.signerSeeds = append(o.signerSeeds, seed)
+	return o
+}
+
+// WithFeeStrategy sets the FeeStrategy used to price the transaction's
+// fee, in place of the network's minimum base fee. Pair it with
+// EstimateFee, e.g. Opts().WithFeeStrategy(microstellar.FeePercentile(90)).
+func (o *Options) WithFeeStrategy(strategy FeeStrategy) *Options {
+	o.feeStrategy = strategy
+	return o
+}
+
+// MemoText returns the memo set via WithMemoText, or "" if none.
+func (o *Options) MemoText() string {
+	if o == nil {
+		return ""
+	}
+	return o.memoText
+}
+
+// SignerSeeds returns the extra cosigner seeds added via WithSigner.
+func (o *Options) SignerSeeds() []string {
+	if o == nil {
+		return nil
+	}
+	return o.signerSeeds
+}
+
+// FeeStrategyOrDefault returns the FeeStrategy set via WithFeeStrategy,
+// or fall back if none was set.
+func (o *Options) FeeStrategyOrDefault(fallback FeeStrategy) FeeStrategy {
+	if o == nil || o.feeStrategy == nil {
+		return fallback
+	}
+	return o.feeStrategy
+}
@@ -0,0 +1,178 @@
+package seqpool
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Result is what came back from submitting one transaction through an
+// AsyncClient: the sequence number it used, the transaction that was
+// built for it (nil if building failed), and the error, if any.
+type Result struct {
+	Seq uint64
+	Tx  SignedTx
+	Err error
+}
+
+// SignedTx is a transaction ready to submit, already sequenced and
+// signed by its caller. It's opaque here; AsyncClient only needs to
+// hand it to Submitter and report what happened.
+type SignedTx interface{}
+
+// Submitter submits a single signed transaction to Horizon. Errors that
+// look like tx_bad_seq should be returned as ErrBadSeq so AsyncClient
+// can trigger a resync instead of treating it like any other failure.
+type Submitter interface {
+	Submit(tx SignedTx) error
+}
+
+// ErrBadSeq should wrap (or be returned directly as) any error a
+// Submitter produces for Horizon's tx_bad_seq failure, so AsyncClient
+// knows to resync the pool rather than just surfacing the error.
+var ErrBadSeq = fmt.Errorf("seqpool: bad sequence number")
+
+// Build turns a reserved sequence number into a signed, submittable
+// transaction. Callers supply this so AsyncClient stays agnostic to how
+// transactions are actually constructed and signed.
+type Build func(seq uint64) (SignedTx, error)
+
+// AsyncClient pipelines transactions from a single source account
+// through a SeqPool and a Submitter, handing out sequence numbers in the
+// order SubmitAsync is called and tracking gaps left by reservations
+// that never committed. The Submit calls themselves run concurrently, so
+// the Submitter may see them, and answer them, in any order.
+type AsyncClient struct {
+	pool   *SeqPool
+	submit Submitter
+
+	mu           sync.Mutex
+	maxCommitted map[string]uint64
+	uncommitted  map[string]map[uint64]bool
+}
+
+// NewAsyncClient builds an AsyncClient backed by pool and submit.
+func NewAsyncClient(pool *SeqPool, submit Submitter) *AsyncClient {
+	return &AsyncClient{
+		pool:         pool,
+		submit:       submit,
+		maxCommitted: make(map[string]uint64),
+		uncommitted:  make(map[string]map[uint64]bool),
+	}
+}
+
+// SubmitAsync reserves a sequence number for source — synchronously, so
+// concurrent calls reserve in the order they're made — then builds a
+// transaction for it via build and submits it in the background,
+// returning a channel the caller can read the Result from once. On a
+// tx_bad_seq failure the pool is resynced so later calls reseed from
+// Horizon.
+func (c *AsyncClient) SubmitAsync(source string, build Build) <-chan Result {
+	out := make(chan Result, 1)
+
+	seq, release, err := c.pool.Reserve(source)
+	if err != nil {
+		out <- Result{Err: err}
+		close(out)
+		return out
+	}
+
+	c.markPending(source, seq)
+
+	go func() {
+		tx, err := build(seq)
+		if err != nil {
+			release(false)
+			c.markDone(source, seq, false)
+			out <- Result{Seq: seq, Err: err}
+			close(out)
+			return
+		}
+
+		err = c.submit.Submit(tx)
+		release(err == nil)
+		c.markDone(source, seq, err == nil)
+
+		if errors.Is(err, ErrBadSeq) {
+			c.pool.Resync(source)
+		}
+
+		out <- Result{Seq: seq, Tx: tx, Err: err}
+		close(out)
+	}()
+
+	return out
+}
+
+// markPending records that seq has been reserved for source and hasn't
+// committed yet.
+func (c *AsyncClient) markPending(source string, seq uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.uncommitted[source] == nil {
+		c.uncommitted[source] = make(map[uint64]bool)
+	}
+	c.uncommitted[source][seq] = true
+}
+
+// markDone records the outcome of a previously pending seq: committed
+// clears it and, if it's the furthest any transaction has gotten for
+// source, advances the high-water mark Gaps measures against.
+func (c *AsyncClient) markDone(source string, seq uint64, committed bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !committed {
+		return
+	}
+
+	delete(c.uncommitted[source], seq)
+	if seq > c.maxCommitted[source] {
+		c.maxCommitted[source] = seq
+	}
+}
+
+// Gaps reports sequence numbers reserved for source whose build or
+// submit failed (or whose SubmitAsync is still in flight) but that fall
+// below the highest sequence number source has successfully committed —
+// meaning a later transaction already superseded them, leaving a
+// permanent hole in the account's sequence chain that needs filling,
+// typically via CancelReplace.
+func (c *AsyncClient) Gaps(source string) []uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	max := c.maxCommitted[source]
+
+	var gaps []uint64
+	for seq := range c.uncommitted[source] {
+		if seq < max {
+			gaps = append(gaps, seq)
+		}
+	}
+	sort.Slice(gaps, func(i, j int) bool { return gaps[i] < gaps[j] })
+
+	return gaps
+}
+
+// CancelReplace builds a no-op transaction (a self-payment of 0, or
+// equivalent) that consumes seq without moving funds, for filling a
+// stuck sequence slot left behind by a transaction that will never be
+// submitted or confirmed. build should construct that no-op using seq.
+// On success, seq is marked committed so it stops showing up in Gaps.
+func (c *AsyncClient) CancelReplace(source string, seq uint64, build Build) error {
+	tx, err := build(seq)
+	if err != nil {
+		return fmt.Errorf("seqpool: building cancel-and-replace for %s/%d: %v", source, seq, err)
+	}
+
+	if err := c.submit.Submit(tx); err != nil {
+		return fmt.Errorf("seqpool: submitting cancel-and-replace for %s/%d: %v", source, seq, err)
+	}
+
+	c.markDone(source, seq, true)
+
+	return nil
+}
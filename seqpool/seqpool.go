@@ -0,0 +1,94 @@
+// Package seqpool hands out Stellar sequence numbers for a source
+// account without blocking each caller on a fresh Horizon load.
+//
+// ms.Pay and friends are strictly sequential today because every call
+// reloads the account and blocks on Horizon for sequence assignment.
+// SeqPool moves that bookkeeping out of the submit path: it tracks
+// in-flight sequence numbers per source account in memory, hands them
+// out monotonically, and only talks to Horizon to seed a new account or
+// to resync after a tx_bad_seq error.
+package seqpool
+
+import (
+	"fmt"
+	"sync"
+)
+
+// AccountLoader fetches the current sequence number for address from
+// Horizon. It's the same shape as microstellar.MicroStellar.LoadAccount
+// plus a Sequence() accessor, kept narrow here so SeqPool doesn't need
+// to import the main package.
+type AccountLoader interface {
+	LoadSequence(address string) (uint64, error)
+}
+
+// SeqPool tracks the next sequence number to hand out for each source
+// account it has seen, reconciling against Horizon on demand.
+type SeqPool struct {
+	loader AccountLoader
+
+	mu   sync.Mutex
+	next map[string]uint64
+}
+
+// New builds a SeqPool that falls back to loader to seed or resync an
+// account's sequence number.
+func New(loader AccountLoader) *SeqPool {
+	return &SeqPool{
+		loader: loader,
+		next:   make(map[string]uint64),
+	}
+}
+
+// Reserve hands out the next sequence number for source, seeding it from
+// Horizon on first use. The returned release func must be called
+// exactly once: release(true) commits the reservation, release(false)
+// gives the sequence number back so the next Reserve call can reuse it,
+// provided no later sequence number has been reserved for source in the
+// meantime (otherwise the slot is left as a gap).
+func (p *SeqPool) Reserve(source string) (seq uint64, release func(committed bool), err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.next[source]; !ok {
+		current, err := p.loader.LoadSequence(source)
+		if err != nil {
+			return 0, nil, fmt.Errorf("seqpool: seeding %s: %v", source, err)
+		}
+		p.next[source] = current + 1
+	}
+
+	seq = p.next[source]
+	p.next[source] = seq + 1
+
+	released := false
+	release = func(committed bool) {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+
+		if released {
+			return
+		}
+		released = true
+
+		if !committed && p.next[source] == seq+1 {
+			// Nobody has reserved past us yet; give the slot back so it's
+			// reused instead of leaving a permanent gap.
+			p.next[source] = seq
+		}
+	}
+
+	return seq, release, nil
+}
+
+// Resync discards the in-memory sequence number for source and reseeds
+// it from Horizon on the next Reserve call. Call this after a
+// tx_bad_seq error, which means some other process (or a previous
+// SeqPool instance) has moved the account's sequence number without us
+// knowing.
+func (p *SeqPool) Resync(source string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delete(p.next, source)
+}
@@ -0,0 +1,172 @@
+package seqpool
+
+import (
+	"sync"
+	"testing"
+)
+
+type fakeLoader struct {
+	seq uint64
+}
+
+func (f *fakeLoader) LoadSequence(address string) (uint64, error) {
+	return f.seq, nil
+}
+
+func TestReserveMonotonic(t *testing.T) {
+	pool := New(&fakeLoader{seq: 100})
+
+	seq1, release1, err := pool.Reserve("GABC")
+	if err != nil {
+		t.Fatalf("Reserve: %v", err)
+	}
+	if seq1 != 101 {
+		t.Fatalf("got seq %d, want 101", seq1)
+	}
+	release1(true)
+
+	seq2, release2, err := pool.Reserve("GABC")
+	if err != nil {
+		t.Fatalf("Reserve: %v", err)
+	}
+	if seq2 != 102 {
+		t.Fatalf("got seq %d, want 102", seq2)
+	}
+	release2(true)
+}
+
+func TestReserveReleaseUncommittedReusesSlot(t *testing.T) {
+	pool := New(&fakeLoader{seq: 100})
+
+	seq, release, err := pool.Reserve("GABC")
+	if err != nil {
+		t.Fatalf("Reserve: %v", err)
+	}
+	release(false)
+
+	seq2, release2, err := pool.Reserve("GABC")
+	if err != nil {
+		t.Fatalf("Reserve: %v", err)
+	}
+	if seq2 != seq {
+		t.Fatalf("got seq %d after uncommitted release, want reused seq %d", seq2, seq)
+	}
+	release2(true)
+}
+
+func TestResyncReseedsFromLoader(t *testing.T) {
+	loader := &fakeLoader{seq: 100}
+	pool := New(loader)
+
+	seq, release, err := pool.Reserve("GABC")
+	if err != nil {
+		t.Fatalf("Reserve: %v", err)
+	}
+	release(true)
+	if seq != 101 {
+		t.Fatalf("got seq %d, want 101", seq)
+	}
+
+	// Simulate Horizon having moved on without us (e.g. tx_bad_seq from a
+	// previous process).
+	loader.seq = 200
+	pool.Resync("GABC")
+
+	seq2, release2, err := pool.Reserve("GABC")
+	if err != nil {
+		t.Fatalf("Reserve after Resync: %v", err)
+	}
+	release2(true)
+	if seq2 != 201 {
+		t.Fatalf("got seq %d after resync, want 201", seq2)
+	}
+}
+
+// fakeOutOfOrderSubmitter delivers Submit results out of order: the
+// first call blocks until a second call arrives and releases it, simulating
+// Horizon responses racing back in a different order than they were
+// sent.
+type fakeOutOfOrderSubmitter struct {
+	mu      sync.Mutex
+	first   chan struct{}
+	calls   int
+	badSeqs map[uint64]bool
+}
+
+func (f *fakeOutOfOrderSubmitter) Submit(tx SignedTx) error {
+	f.mu.Lock()
+	f.calls++
+	call := f.calls
+	f.mu.Unlock()
+
+	seq := tx.(uint64)
+	if call == 1 {
+		<-f.first
+	} else {
+		close(f.first)
+	}
+
+	if f.badSeqs[seq] {
+		return ErrBadSeq
+	}
+	return nil
+}
+
+func TestAsyncClientHandlesOutOfOrderResponses(t *testing.T) {
+	loader := &fakeLoader{seq: 100}
+	pool := New(loader)
+	submitter := &fakeOutOfOrderSubmitter{first: make(chan struct{})}
+	client := NewAsyncClient(pool, submitter)
+
+	build := func(seq uint64) (SignedTx, error) { return seq, nil }
+
+	results := make([]<-chan Result, 0, 2)
+	results = append(results, client.SubmitAsync("GABC", build))
+	results = append(results, client.SubmitAsync("GABC", build))
+
+	r1 := <-results[0]
+	r2 := <-results[1]
+
+	if r1.Err != nil || r2.Err != nil {
+		t.Fatalf("unexpected errors: r1=%v r2=%v", r1.Err, r2.Err)
+	}
+	if r1.Seq == r2.Seq {
+		t.Fatalf("expected distinct sequence numbers, got %d twice", r1.Seq)
+	}
+}
+
+// fakeSubmitter is a plain, non-blocking Submitter for tests that submit
+// sequentially and don't need fakeOutOfOrderSubmitter's call-ordering
+// games.
+type fakeSubmitter struct {
+	badSeqs map[uint64]bool
+}
+
+func (f *fakeSubmitter) Submit(tx SignedTx) error {
+	if f.badSeqs[tx.(uint64)] {
+		return ErrBadSeq
+	}
+	return nil
+}
+
+func TestAsyncClientResyncsOnBadSeq(t *testing.T) {
+	loader := &fakeLoader{seq: 100}
+	pool := New(loader)
+	submitter := &fakeSubmitter{badSeqs: map[uint64]bool{101: true}}
+	client := NewAsyncClient(pool, submitter)
+
+	loader.seq = 100
+	res := <-client.SubmitAsync("GABC", func(seq uint64) (SignedTx, error) { return seq, nil })
+	if res.Err != ErrBadSeq {
+		t.Fatalf("got err %v, want ErrBadSeq", res.Err)
+	}
+
+	loader.seq = 150
+	res2 := <-client.SubmitAsync("GABC", func(seq uint64) (SignedTx, error) { return seq, nil })
+	if res2.Err != nil {
+		t.Fatalf("unexpected error after resync: %v", res2.Err)
+	}
+	if res2.Seq != 151 {
+		t.Fatalf("got seq %d after resync, want 151", res2.Seq)
+	}
+}
@@ -0,0 +1,66 @@
+package microstellar
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/0xfe/microstellar/seqpool"
+)
+
+// SeqPoolLoader adapts a MicroStellar instance to seqpool.AccountLoader,
+// so a seqpool.SeqPool can seed and resync its in-memory sequence
+// numbers from the same Horizon client ms talks to.
+type SeqPoolLoader struct {
+	ms *MicroStellar
+}
+
+// NewSeqPoolLoader builds a SeqPoolLoader backed by ms.
+func NewSeqPoolLoader(ms *MicroStellar) *SeqPoolLoader {
+	return &SeqPoolLoader{ms: ms}
+}
+
+// LoadSequence implements seqpool.AccountLoader.
+func (l *SeqPoolLoader) LoadSequence(address string) (uint64, error) {
+	account, err := l.ms.LoadAccount(address)
+	if err != nil {
+		return 0, fmt.Errorf("microstellar: SeqPoolLoader: %v", err)
+	}
+
+	return account.Sequence(), nil
+}
+
+// SeqPoolSubmitter adapts a MicroStellar instance to seqpool.Submitter,
+// submitting the signed envelopes a seqpool.Build produces (e.g. via
+// SignTx) and classifying Horizon's tx_bad_seq rejections as
+// seqpool.ErrBadSeq so an AsyncClient knows to resync its pool.
+type SeqPoolSubmitter struct {
+	ms *MicroStellar
+}
+
+// NewSeqPoolSubmitter builds a SeqPoolSubmitter backed by ms.
+func NewSeqPoolSubmitter(ms *MicroStellar) *SeqPoolSubmitter {
+	return &SeqPoolSubmitter{ms: ms}
+}
+
+// Submit implements seqpool.Submitter. tx must be a string: a signed
+// transaction envelope encoded as base64 XDR, the same shape SignTx
+// produces.
+func (s *SeqPoolSubmitter) Submit(tx seqpool.SignedTx) error {
+	envelopeXDR, ok := tx.(string)
+	if !ok {
+		return fmt.Errorf("microstellar: SeqPoolSubmitter: tx is %T, want a base64 XDR string", tx)
+	}
+
+	if _, err := s.ms.client.SubmitTransaction(envelopeXDR); err != nil {
+		// defaultHorizonClient folds Horizon's failure body into err's
+		// text rather than returning it separately, so result_codes like
+		// tx_bad_seq have to be recognized this way rather than parsed
+		// out of a structured response.
+		if strings.Contains(err.Error(), "tx_bad_seq") {
+			return seqpool.ErrBadSeq
+		}
+		return fmt.Errorf("microstellar: SeqPoolSubmitter: %v", err)
+	}
+
+	return nil
+}
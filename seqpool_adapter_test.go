@@ -0,0 +1,79 @@
+package microstellar
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/0xfe/microstellar/seqpool"
+)
+
+// fakeSeqPoolHorizon is a minimal HorizonClient that serves a single
+// account and lets a test script SubmitTransaction's outcome, for
+// exercising SeqPoolLoader/SeqPoolSubmitter without a network.
+type fakeSeqPoolHorizon struct {
+	accounts  map[string]string // address -> raw Horizon account JSON
+	submitErr error
+}
+
+func (f *fakeSeqPoolHorizon) LoadAccount(address string) ([]byte, error) {
+	raw, ok := f.accounts[address]
+	if !ok {
+		return nil, fmt.Errorf("fakeSeqPoolHorizon: no account for %s", address)
+	}
+	return []byte(raw), nil
+}
+
+func (f *fakeSeqPoolHorizon) SubmitTransaction(envelopeXDR string) ([]byte, error) {
+	if f.submitErr != nil {
+		return nil, f.submitErr
+	}
+	return []byte(`{}`), nil
+}
+
+func (f *fakeSeqPoolHorizon) LoadFeeStats() ([]byte, error) {
+	return nil, fmt.Errorf("fakeSeqPoolHorizon: LoadFeeStats not implemented")
+}
+
+func TestSeqPoolLoaderLoadSequence(t *testing.T) {
+	fake := &fakeSeqPoolHorizon{accounts: map[string]string{
+		"MASTER": accountJSON("100", 1, 0, 0, 0),
+	}}
+	ms := New("test", WithHorizonClient(fake))
+
+	seq, err := NewSeqPoolLoader(ms).LoadSequence("MASTER")
+	if err != nil {
+		t.Fatalf("LoadSequence: %v", err)
+	}
+	if seq != 100 {
+		t.Fatalf("got sequence %d, want 100", seq)
+	}
+}
+
+func TestSeqPoolSubmitterSubmit(t *testing.T) {
+	fake := &fakeSeqPoolHorizon{}
+	ms := New("test", WithHorizonClient(fake))
+
+	if err := NewSeqPoolSubmitter(ms).Submit("fake-envelope-xdr"); err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+}
+
+func TestSeqPoolSubmitterSubmitWrongType(t *testing.T) {
+	ms := New("test", WithHorizonClient(&fakeSeqPoolHorizon{}))
+
+	if err := NewSeqPoolSubmitter(ms).Submit(42); err == nil {
+		t.Fatal("Submit: want an error for a non-string tx, got nil")
+	}
+}
+
+func TestSeqPoolSubmitterClassifiesBadSeq(t *testing.T) {
+	fake := &fakeSeqPoolHorizon{
+		submitErr: fmt.Errorf(`POST /transactions: 400 Bad Request: {"extras":{"result_codes":{"transaction":"tx_bad_seq"}}}`),
+	}
+	ms := New("test", WithHorizonClient(fake))
+
+	err := NewSeqPoolSubmitter(ms).Submit("fake-envelope-xdr")
+	if err != seqpool.ErrBadSeq {
+		t.Fatalf("got error %v, want seqpool.ErrBadSeq", err)
+	}
+}
@@ -0,0 +1,506 @@
+package microstellar
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/txnbuild"
+	"github.com/stellar/go/xdr"
+)
+
+// Verdict summarizes whether a simulated or decoded transaction would be
+// accepted by the network.
+type Verdict int
+
+// Verdict values returned by SimulateTx.
+const (
+	// OK means the transaction is fully signed and should submit cleanly.
+	OK Verdict = iota
+	// UnderweightForOperation means the collected signer weight doesn't
+	// meet the threshold required by at least one operation.
+	UnderweightForOperation
+	// OverweightWarning means more signing weight was collected than any
+	// operation requires. Despite the name this is effectively fatal:
+	// Stellar rejects a transaction with more signatures than its
+	// operations need with tx_bad_auth_extra, so submitting is expected
+	// to fail just like the other non-OK verdicts.
+	OverweightWarning
+	// MissingTrustline means a payment's destination has no trustline
+	// for the asset being sent.
+	MissingTrustline
+	// InsufficientBalance means a payment would overdraw the source
+	// account's balance of the relevant asset.
+	InsufficientBalance
+)
+
+func (v Verdict) String() string {
+	switch v {
+	case OK:
+		return "OK"
+	case UnderweightForOperation:
+		return "UnderweightForOperation"
+	case OverweightWarning:
+		return "OverweightWarning"
+	case MissingTrustline:
+		return "MissingTrustline"
+	case InsufficientBalance:
+		return "InsufficientBalance"
+	default:
+		return fmt.Sprintf("Verdict(%d)", int(v))
+	}
+}
+
+// OpVerdict is the per-operation portion of a TxSimulation: the
+// threshold class the op falls under, the weight collected against it,
+// and what that means for submission.
+type OpVerdict struct {
+	Op        Op
+	Threshold ThresholdClass
+	Weight    uint32
+	Required  uint32
+	Verdict   Verdict
+	Reason    string
+}
+
+// TxSimulation is the result of SimulateTx: everything a caller needs to
+// know about whether a transaction would be accepted, without
+// submitting it.
+type TxSimulation struct {
+	Source   string
+	Ops      []OpVerdict
+	Fee      uint64
+	Sequence uint64
+	Verdict  Verdict
+	Reason   string
+}
+
+// ThresholdClass is one of the three Stellar signing thresholds an
+// operation can require.
+type ThresholdClass int
+
+// ThresholdClass values, in increasing order of weight typically
+// required.
+const (
+	ThresholdLow ThresholdClass = iota
+	ThresholdMedium
+	ThresholdHigh
+)
+
+func (t ThresholdClass) String() string {
+	switch t {
+	case ThresholdLow:
+		return "low"
+	case ThresholdMedium:
+		return "medium"
+	case ThresholdHigh:
+		return "high"
+	default:
+		return fmt.Sprintf("ThresholdClass(%d)", int(t))
+	}
+}
+
+// Op is a single operation SimulateTx can check. Implementations are
+// built by PaymentOp, ChangeTrustOp and SetOptionsOp.
+type Op interface {
+	isOp()
+}
+
+// paymentOp is the concrete Op built by PaymentOp.
+type paymentOp struct {
+	Destination string
+	Amount      string
+	// Asset is nil for a native (lumen) payment.
+	Asset *Asset
+}
+
+func (*paymentOp) isOp() {}
+
+// PaymentOp builds the payment operation SimulateTx should check. Pass a
+// nil asset for a native payment.
+func PaymentOp(destination, amount string, asset *Asset) Op {
+	return &paymentOp{Destination: destination, Amount: amount, Asset: asset}
+}
+
+// changeTrustOp is the concrete Op built by ChangeTrustOp.
+type changeTrustOp struct {
+	Asset *Asset
+	Limit string
+}
+
+func (*changeTrustOp) isOp() {}
+
+// ChangeTrustOp builds the trustline operation (as run by
+// CreateTrustLine/RemoveTrustLine) SimulateTx should check. Pass "0" as
+// limit to simulate removing the trustline.
+func ChangeTrustOp(asset *Asset, limit string) Op {
+	return &changeTrustOp{Asset: asset, Limit: limit}
+}
+
+// setOptionsOp is the concrete Op built by SetOptionsOp.
+type setOptionsOp struct{}
+
+func (*setOptionsOp) isOp() {}
+
+// SetOptionsOp builds the account-options operation (as run by
+// AddSigner, SetMasterWeight and SetThresholds) SimulateTx should check.
+func SetOptionsOp() Op {
+	return &setOptionsOp{}
+}
+
+// toTxnBuildOps converts Ops built via PaymentOp, ChangeTrustOp and
+// SetOptionsOp into the txnbuild operations buildSignedEnvelope needs to
+// actually build a transaction. A genericOp — only ever produced by
+// DecodeEnvelope — can't be converted back, since it doesn't carry a
+// typed payload.
+func toTxnBuildOps(ops []Op) ([]txnbuild.Operation, error) {
+	out := make([]txnbuild.Operation, 0, len(ops))
+	for _, op := range ops {
+		switch v := op.(type) {
+		case *paymentOp:
+			asset, err := v.Asset.toTxnBuildAsset()
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, &txnbuild.Payment{Destination: v.Destination, Amount: v.Amount, Asset: asset})
+		case *changeTrustOp:
+			asset, err := v.Asset.toTxnBuildAsset()
+			if err != nil {
+				return nil, err
+			}
+			ctAsset, err := asset.ToChangeTrustAsset()
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, &txnbuild.ChangeTrust{Line: ctAsset, Limit: v.Limit})
+		case *setOptionsOp:
+			out = append(out, &txnbuild.SetOptions{})
+		default:
+			return nil, fmt.Errorf("op %T can't be built into a transaction", op)
+		}
+	}
+
+	return out, nil
+}
+
+// SignTx builds and signs the transaction ops would produce for
+// sourceAddress, returning its envelope as base64 XDR without submitting
+// it — for example to inspect via DecodeEnvelope, or hold for later
+// resubmission through BumpFee if its fee turns out to be too low to
+// get included.
+func (ms *MicroStellar) SignTx(sourceAddress string, ops []Op, opts *Options) (string, error) {
+	txnOps, err := toTxnBuildOps(ops)
+	if err != nil {
+		return "", fmt.Errorf("microstellar: SignTx: %v", err)
+	}
+
+	env, err := ms.buildSignedEnvelope(sourceAddress, txnOps, opts)
+	if err != nil {
+		return "", fmt.Errorf("microstellar: SignTx: %v", err)
+	}
+
+	return env, nil
+}
+
+// SimulateTx checks, without building or submitting it, whether the
+// transaction that ops would produce for sourceAddress (an address or,
+// as with SignTx, a seed) would be accepted: the aggregate signing
+// weight collected from opts' signers
+// against the source account's current signer set and threshold for
+// each op, whether the destination of any payment is missing a
+// trustline, and whether the source account has enough balance to cover
+// it. The overall result is an OK/warning/failure Verdict with a
+// human-readable Reason.
+func (ms *MicroStellar) SimulateTx(sourceAddress string, ops []Op, opts *Options) (*TxSimulation, error) {
+	kp, err := keypair.Parse(sourceAddress)
+	if err != nil {
+		return nil, fmt.Errorf("microstellar: SimulateTx: parsing source: %v", err)
+	}
+	address := kp.Address()
+
+	account, err := ms.LoadAccount(address)
+	if err != nil {
+		return nil, fmt.Errorf("microstellar: SimulateTx: loading %s: %v", address, err)
+	}
+
+	weight := collectedWeight(account, opts)
+
+	sim := &TxSimulation{
+		Source:   address,
+		Sequence: account.Sequence() + 1,
+		Fee:      baseFee * uint64(len(ops)),
+		Verdict:  OK,
+	}
+
+	var maxRequired uint32
+	for _, op := range ops {
+		ov := ms.simulateOp(account, op, weight)
+		if ov.Required > maxRequired {
+			maxRequired = ov.Required
+		}
+		if ov.Verdict != OK && sim.Verdict == OK {
+			sim.Verdict = ov.Verdict
+			sim.Reason = ov.Reason
+		}
+
+		sim.Ops = append(sim.Ops, ov)
+	}
+
+	if sim.Verdict == OK && len(ops) > 0 && weight > maxRequired {
+		sim.Verdict = OverweightWarning
+		sim.Reason = fmt.Sprintf("collected weight %d exceeds the %d required by any operation", weight, maxRequired)
+	}
+
+	return sim, nil
+}
+
+// simulateOp checks a single op's threshold, then, for a payment, its
+// destination's trustline and the source's balance.
+func (ms *MicroStellar) simulateOp(account *Account, op Op, weight uint32) OpVerdict {
+	threshold := thresholdClassFor(op)
+	required := thresholdWeight(account, threshold)
+	// A threshold of 0 still needs at least one signature with nonzero
+	// weight; a transaction signed only by zero-weight keys is rejected.
+	if required == 0 {
+		required = 1
+	}
+
+	ov := OpVerdict{Op: op, Threshold: threshold, Weight: weight, Required: required, Verdict: OK}
+
+	if weight < required {
+		ov.Verdict = UnderweightForOperation
+		ov.Reason = fmt.Sprintf("collected weight %d is below the %s threshold of %d", weight, threshold, required)
+		return ov
+	}
+
+	pay, ok := op.(*paymentOp)
+	if !ok {
+		return ov
+	}
+
+	if pay.Asset != nil {
+		dest, err := ms.LoadAccount(pay.Destination)
+		// An empty balance string is this library's way of reporting "no
+		// trustline"; see Account.GetBalance.
+		if err != nil || dest.GetBalance(pay.Asset) == "" {
+			ov.Verdict = MissingTrustline
+			ov.Reason = fmt.Sprintf("%s has no trustline for %s", pay.Destination, pay.Asset)
+			return ov
+		}
+	}
+
+	if !hasSufficientBalance(account, pay) {
+		ov.Verdict = InsufficientBalance
+		ov.Reason = fmt.Sprintf("%s does not have enough balance to pay %s", account.GetNativeBalance(), pay.Amount)
+	}
+
+	return ov
+}
+
+func hasSufficientBalance(account *Account, pay *paymentOp) bool {
+	balance := account.GetNativeBalance()
+	if pay.Asset != nil {
+		balance = account.GetBalance(pay.Asset)
+	}
+
+	available, err := strconv.ParseFloat(balance, 64)
+	if err != nil {
+		return false
+	}
+
+	amount, err := strconv.ParseFloat(pay.Amount, 64)
+	if err != nil {
+		return false
+	}
+
+	return available >= amount
+}
+
+// collectedWeight sums the signing weight of the source account's
+// master key (if its weight hasn't been zeroed) plus every extra signer
+// supplied via opts, matched to the account's signer set by public
+// address rather than by seed.
+func collectedWeight(account *Account, opts *Options) uint32 {
+	weight := account.GetMasterWeight()
+
+	weightByAddress := make(map[string]uint32, len(account.Signers))
+	for _, s := range account.Signers {
+		weightByAddress[s.PublicKey] = s.Weight
+	}
+
+	for _, seed := range opts.SignerSeeds() {
+		address, err := addressFromSeed(seed)
+		if err != nil {
+			continue
+		}
+		weight += weightByAddress[address]
+	}
+
+	return weight
+}
+
+// addressFromSeed derives the public address a signing seed corresponds
+// to, so collected signer weight can be looked up in an account's
+// signer set (which is keyed by address, not seed).
+func addressFromSeed(seed string) (string, error) {
+	kp, err := keypair.Parse(seed)
+	if err != nil {
+		return "", fmt.Errorf("parsing signer seed: %v", err)
+	}
+
+	return kp.Address(), nil
+}
+
+// thresholdWeight returns the weight account requires for operations in
+// the given ThresholdClass.
+func thresholdWeight(account *Account, threshold ThresholdClass) uint32 {
+	switch threshold {
+	case ThresholdHigh:
+		return account.GetHighThreshold()
+	case ThresholdMedium:
+		return account.GetMedThreshold()
+	default:
+		return account.GetLowThreshold()
+	}
+}
+
+// thresholdClassFor reports which of an account's three thresholds op
+// is checked against, per Stellar's standard threshold assignment:
+// payments and trustline changes require medium, account-options changes
+// (adding a signer, changing master weight or thresholds) require high.
+func thresholdClassFor(op Op) ThresholdClass {
+	switch op.(type) {
+	case *paymentOp, *changeTrustOp:
+		return ThresholdMedium
+	case *setOptionsOp:
+		return ThresholdHigh
+	default:
+		return ThresholdLow
+	}
+}
+
+// DecodedTx is the human-readable view of a signed or unsigned
+// transaction envelope, as produced by DecodeEnvelope.
+type DecodedTx struct {
+	Source   string
+	Sequence uint64
+	Fee      uint64
+	Memo     string
+	Ops      []Op
+	Signers  []string
+}
+
+// DecodeEnvelope parses a base64-encoded transaction envelope (as
+// produced by SignTx or collected out-of-band from other signers) and
+// returns its operations, memo, source account and the signatures
+// already present on it. It's meant for a coordinator collecting
+// partial multi-sig signatures, so it can display what's on an envelope
+// before adding its own signature.
+//
+// Signers is reported as the hex-encoded 4-byte signature hints on the
+// envelope, not full addresses — a hint only narrows a signature down
+// to the last 4 bytes of a signer's public key, since that's all a
+// transaction envelope carries.
+func (ms *MicroStellar) DecodeEnvelope(xdrB64 string) (*DecodedTx, error) {
+	generic, err := txnbuild.TransactionFromXDR(xdrB64)
+	if err != nil {
+		return nil, fmt.Errorf("microstellar: DecodeEnvelope: parsing envelope: %v", err)
+	}
+
+	tx, ok := generic.Transaction()
+	if !ok {
+		return nil, fmt.Errorf("microstellar: DecodeEnvelope: envelope is a fee-bump transaction, not a plain transaction")
+	}
+
+	env := tx.ToXDR()
+	if env.V1 == nil {
+		return nil, fmt.Errorf("microstellar: DecodeEnvelope: unsupported envelope version")
+	}
+
+	sourceAccountID := env.V1.Tx.SourceAccount.ToAccountId()
+	source, err := sourceAccountID.GetAddress()
+	if err != nil {
+		return nil, fmt.Errorf("microstellar: DecodeEnvelope: decoding source account: %v", err)
+	}
+
+	ops, err := opsFromTxnBuild(tx.Operations())
+	if err != nil {
+		return nil, fmt.Errorf("microstellar: DecodeEnvelope: %v", err)
+	}
+
+	return &DecodedTx{
+		Source:   source,
+		Sequence: uint64(env.V1.Tx.SeqNum),
+		Fee:      uint64(env.V1.Tx.Fee),
+		Memo:     memoText(tx.Memo()),
+		Ops:      ops,
+		Signers:  signerHints(env.V1.Signatures),
+	}, nil
+}
+
+// memoText returns m's text, or "" if m isn't a text memo.
+func memoText(m txnbuild.Memo) string {
+	if mt, ok := m.(txnbuild.MemoText); ok {
+		return string(mt)
+	}
+
+	return ""
+}
+
+// signerHints renders each signature's 4-byte hint as hex, in envelope
+// order.
+func signerHints(sigs []xdr.DecoratedSignature) []string {
+	hints := make([]string, 0, len(sigs))
+	for _, sig := range sigs {
+		hints = append(hints, hex.EncodeToString(sig.Hint[:]))
+	}
+
+	return hints
+}
+
+// genericOp is a decoded operation other than a payment, which
+// opsFromTxnBuild doesn't map to a typed Op; Kind names the underlying
+// txnbuild operation type for display. Unlike paymentOp, it can't be fed
+// back into SignTx, and SimulateTx checks it only against the low
+// threshold.
+type genericOp struct {
+	Kind string
+}
+
+func (*genericOp) isOp() {}
+
+// opsFromTxnBuild converts decoded txnbuild operations into this
+// package's Op, recognizing payments and falling back to genericOp for
+// everything else.
+func opsFromTxnBuild(raw []txnbuild.Operation) ([]Op, error) {
+	ops := make([]Op, 0, len(raw))
+	for _, o := range raw {
+		switch v := o.(type) {
+		case *txnbuild.Payment:
+			asset, err := assetFromTxnBuild(v.Asset)
+			if err != nil {
+				return nil, err
+			}
+			ops = append(ops, &paymentOp{Destination: v.Destination, Amount: v.Amount, Asset: asset})
+		default:
+			ops = append(ops, &genericOp{Kind: fmt.Sprintf("%T", o)})
+		}
+	}
+
+	return ops, nil
+}
+
+// assetFromTxnBuild converts a txnbuild.Asset into this package's Asset.
+func assetFromTxnBuild(a txnbuild.Asset) (*Asset, error) {
+	if a == nil || a.IsNative() {
+		return NativeAsset, nil
+	}
+
+	assetType := Credit4Type
+	if len(a.GetCode()) > 4 {
+		assetType = Credit12Type
+	}
+
+	return NewAsset(a.GetCode(), a.GetIssuer(), assetType), nil
+}
@@ -0,0 +1,213 @@
+package microstellar
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stellar/go/keypair"
+)
+
+// fakeSimHorizon is a minimal HorizonClient that serves canned account JSON
+// by address, for exercising SimulateTx and DecodeEnvelope without a
+// network.
+type fakeSimHorizon struct {
+	accounts map[string]string // address -> raw Horizon account JSON
+}
+
+func (f *fakeSimHorizon) LoadAccount(address string) ([]byte, error) {
+	raw, ok := f.accounts[address]
+	if !ok {
+		return nil, fmt.Errorf("fakeSimHorizon: no account for %s", address)
+	}
+	return []byte(raw), nil
+}
+
+func (f *fakeSimHorizon) SubmitTransaction(envelopeXDR string) ([]byte, error) {
+	return []byte(`{}`), nil
+}
+
+func (f *fakeSimHorizon) LoadFeeStats() ([]byte, error) {
+	return nil, fmt.Errorf("fakeSimHorizon: LoadFeeStats not implemented")
+}
+
+func accountJSON(sequence string, masterWeight, low, med, high uint32, extraSigners ...AccountSigner) string {
+	signers := fmt.Sprintf(`{"type":"ed25519_public_key","weight":%d,"key":"%s"}`, masterWeight, "MASTER")
+	for _, s := range extraSigners {
+		signers += fmt.Sprintf(`,{"type":"%s","weight":%d,"key":"%s"}`, s.Type, s.Weight, s.PublicKey)
+	}
+
+	return fmt.Sprintf(`{
+		"sequence": "%s",
+		"balances": [{"asset_type":"native","balance":"1000.0000000"}],
+		"thresholds": {"low_threshold":%d,"med_threshold":%d,"high_threshold":%d},
+		"signers": [%s]
+	}`, sequence, low, med, high, signers)
+}
+
+func TestSimulateTxUnderweightForOperation(t *testing.T) {
+	fake := &fakeSimHorizon{accounts: map[string]string{
+		"MASTER": accountJSON("100", 1, 0, 2, 0),
+	}}
+	ms := New("test", WithHorizonClient(fake))
+
+	sim, err := ms.SimulateTx("MASTER", []Op{PaymentOp("GDEST", "10", nil)}, nil)
+	if err != nil {
+		t.Fatalf("SimulateTx: %v", err)
+	}
+
+	if sim.Verdict != UnderweightForOperation {
+		t.Fatalf("got verdict %v, want UnderweightForOperation", sim.Verdict)
+	}
+}
+
+func TestSimulateTxOverweightWarning(t *testing.T) {
+	extraKP, err := keypair.Random()
+	if err != nil {
+		t.Fatalf("keypair.Random: %v", err)
+	}
+
+	fake := &fakeSimHorizon{accounts: map[string]string{
+		"MASTER": accountJSON("100", 1, 0, 1, 0, AccountSigner{Type: "ed25519_public_key", Weight: 5, PublicKey: extraKP.Address()}),
+	}}
+	ms := New("test", WithHorizonClient(fake))
+
+	opts := Opts().WithSigner(extraKP.Seed())
+	sim, err := ms.SimulateTx("MASTER", []Op{PaymentOp("GDEST", "10", nil)}, opts)
+	if err != nil {
+		t.Fatalf("SimulateTx: %v", err)
+	}
+
+	if sim.Verdict != OverweightWarning {
+		t.Fatalf("got verdict %v, want OverweightWarning", sim.Verdict)
+	}
+}
+
+func TestSimulateTxInsufficientBalance(t *testing.T) {
+	fake := &fakeSimHorizon{accounts: map[string]string{
+		"MASTER": accountJSON("100", 1, 0, 0, 0),
+	}}
+	ms := New("test", WithHorizonClient(fake))
+
+	sim, err := ms.SimulateTx("MASTER", []Op{PaymentOp("GDEST", "10000", nil)}, nil)
+	if err != nil {
+		t.Fatalf("SimulateTx: %v", err)
+	}
+
+	if sim.Verdict != InsufficientBalance {
+		t.Fatalf("got verdict %v, want InsufficientBalance", sim.Verdict)
+	}
+}
+
+func TestSimulateTxMissingTrustline(t *testing.T) {
+	asset := NewAsset("USD", "GISSUER", Credit4Type)
+	fake := &fakeSimHorizon{accounts: map[string]string{
+		"MASTER": accountJSON("100", 1, 0, 0, 0),
+		"GDEST":  accountJSON("1", 1, 0, 0, 0),
+	}}
+	ms := New("test", WithHorizonClient(fake))
+
+	sim, err := ms.SimulateTx("MASTER", []Op{PaymentOp("GDEST", "10", asset)}, nil)
+	if err != nil {
+		t.Fatalf("SimulateTx: %v", err)
+	}
+
+	if sim.Verdict != MissingTrustline {
+		t.Fatalf("got verdict %v, want MissingTrustline", sim.Verdict)
+	}
+}
+
+func TestSimulateTxSetOptionsRequiresHighThreshold(t *testing.T) {
+	fake := &fakeSimHorizon{accounts: map[string]string{
+		"MASTER": accountJSON("100", 1, 0, 1, 2),
+	}}
+	ms := New("test", WithHorizonClient(fake))
+
+	sim, err := ms.SimulateTx("MASTER", []Op{SetOptionsOp()}, nil)
+	if err != nil {
+		t.Fatalf("SimulateTx: %v", err)
+	}
+
+	if sim.Verdict != UnderweightForOperation {
+		t.Fatalf("got verdict %v, want UnderweightForOperation", sim.Verdict)
+	}
+	if sim.Ops[0].Threshold != ThresholdHigh {
+		t.Fatalf("got threshold %v, want ThresholdHigh", sim.Ops[0].Threshold)
+	}
+}
+
+func TestSimulateTxChangeTrustRequiresMediumThreshold(t *testing.T) {
+	asset := NewAsset("USD", "GISSUER", Credit4Type)
+	fake := &fakeSimHorizon{accounts: map[string]string{
+		"MASTER": accountJSON("100", 1, 0, 1, 0),
+	}}
+	ms := New("test", WithHorizonClient(fake))
+
+	sim, err := ms.SimulateTx("MASTER", []Op{ChangeTrustOp(asset, "1000")}, nil)
+	if err != nil {
+		t.Fatalf("SimulateTx: %v", err)
+	}
+
+	if sim.Verdict != OK {
+		t.Fatalf("got verdict %v, want OK", sim.Verdict)
+	}
+	if sim.Ops[0].Threshold != ThresholdMedium {
+		t.Fatalf("got threshold %v, want ThresholdMedium", sim.Ops[0].Threshold)
+	}
+}
+
+func TestDecodeEnvelopeRoundTrip(t *testing.T) {
+	sourceKP, err := keypair.Random()
+	if err != nil {
+		t.Fatalf("keypair.Random: %v", err)
+	}
+	destKP, err := keypair.Random()
+	if err != nil {
+		t.Fatalf("keypair.Random: %v", err)
+	}
+	issuerKP, err := keypair.Random()
+	if err != nil {
+		t.Fatalf("keypair.Random: %v", err)
+	}
+
+	fake := &fakeSimHorizon{accounts: map[string]string{
+		sourceKP.Address(): accountJSON("100", 1, 0, 0, 0),
+	}}
+
+	ms := New("test", WithHorizonClient(fake))
+
+	asset := NewAsset("USD", issuerKP.Address(), Credit4Type)
+	opts := Opts().WithMemoText("hello")
+	envB64, err := ms.SignTx(sourceKP.Seed(), []Op{PaymentOp(destKP.Address(), "42.5", asset)}, opts)
+	if err != nil {
+		t.Fatalf("SignTx: %v", err)
+	}
+
+	decoded, err := ms.DecodeEnvelope(envB64)
+	if err != nil {
+		t.Fatalf("DecodeEnvelope: %v", err)
+	}
+
+	if decoded.Source != sourceKP.Address() {
+		t.Errorf("got source %s, want %s", decoded.Source, sourceKP.Address())
+	}
+	if decoded.Memo != "hello" {
+		t.Errorf("got memo %q, want %q", decoded.Memo, "hello")
+	}
+	if len(decoded.Signers) != 1 {
+		t.Fatalf("got %d signer hints, want 1", len(decoded.Signers))
+	}
+	if len(decoded.Ops) != 1 {
+		t.Fatalf("got %d ops, want 1", len(decoded.Ops))
+	}
+
+	pay, ok := decoded.Ops[0].(*paymentOp)
+	if !ok {
+		t.Fatalf("decoded op is %T, want *paymentOp", decoded.Ops[0])
+	}
+	if pay.Destination != destKP.Address() || pay.Amount != "42.5000000" {
+		t.Errorf("got payment %+v, want destination %s amount 42.5000000", pay, destKP.Address())
+	}
+	if pay.Asset == nil || pay.Asset.Code != "USD" || pay.Asset.Issuer != issuerKP.Address() {
+		t.Errorf("got asset %+v, want USD:%s", pay.Asset, issuerKP.Address())
+	}
+}